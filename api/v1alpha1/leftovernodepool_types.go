@@ -110,18 +110,40 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Condition types
 const (
 	ConditionReady = "Ready"
+	// ConditionOwnershipConflict is True while another field manager changes
+	// the NodePool requirements Leftover maintains.
+	ConditionOwnershipConflict = "OwnershipConflict"
+	// ConditionPaused is True while spec.paused freezes the NodePool.
+	ConditionPaused = "Paused"
 )
 
 // LeftoverNodePoolSpec defines the desired state of LeftoverNodePool
-// Exactly one of nodeClassName or nodeClassSelector must be set.
-// +kubebuilder:validation:XValidation:rule="has(self.nodeClassName) || (has(self.nodeClassSelector) && size(self.nodeClassSelector) > 0)",message="one of nodeClassName or nodeClassSelector must be set"
-// +kubebuilder:validation:XValidation:rule="!(has(self.nodeClassName) && has(self.nodeClassSelector))",message="only one of nodeClassName or nodeClassSelector may be set"
+// At most one of nodeClassName, nodeClassSelector or nodeClassTemplate may be set;
+// without one, nodeClassMappings must cover the candidate instance types. With
+// nodePoolRef the node class of the adopted NodePool is used instead.
+// +kubebuilder:validation:XValidation:rule="has(self.nodeClassName) || (has(self.nodeClassSelector) && size(self.nodeClassSelector) > 0) || has(self.nodeClassTemplate) || (has(self.nodeClassMappings) && size(self.nodeClassMappings) > 0) || has(self.nodePoolRef)",message="one of nodeClassName, nodeClassSelector, nodeClassTemplate, nodeClassMappings or nodePoolRef must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.nodePoolRef) || !(has(self.nodeClassName) || has(self.nodeClassSelector) || has(self.nodeClassTemplate) || has(self.nodeClassMappings))",message="nodePoolRef uses the node class of the adopted NodePool; nodeClassName, nodeClassSelector, nodeClassTemplate and nodeClassMappings must not be set"
+// +kubebuilder:validation:XValidation:rule="[has(self.nodeClassName), has(self.nodeClassSelector), has(self.nodeClassTemplate)].filter(x, x).size() <= 1",message="only one of nodeClassName, nodeClassSelector or nodeClassTemplate may be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.nodeClassTemplate) || !has(self.nodeClassRef) || self.nodeClassRef.kind == 'EC2NodeClass'",message="nodeClassTemplate renders an EC2NodeClass and cannot be used with Auto Mode NodeClasses"
+// +kubebuilder:validation:XValidation:rule="!has(self.nodePoolRef) || !has(self.rolloutStrategy) || self.rolloutStrategy.type != 'BlueGreen'",message="rolloutStrategy BlueGreen creates NodePools and cannot be used with nodePoolRef"
+// +kubebuilder:validation:XValidation:rule="!has(self.diversification) || !has(self.nodePoolRef)",message="diversification creates NodePools and cannot be used with nodePoolRef"
+// +kubebuilder:validation:XValidation:rule="!has(self.diversification) || !has(self.rolloutStrategy) || self.rolloutStrategy.type != 'Additive'",message="diversified NodePools are per offering; rolloutStrategy Additive does not apply"
+// +kubebuilder:validation:XValidation:rule="!has(self.outputMode) || self.outputMode != 'NodeOverlay' || !has(self.diversification)",message="outputMode NodeOverlay cannot be combined with diversification"
+// +kubebuilder:validation:XValidation:rule="!has(self.outputMode) || self.outputMode != 'NodeOverlay' || !has(self.rolloutStrategy) || self.rolloutStrategy.type != 'Additive'",message="outputMode NodeOverlay leaves the requirements broad; rolloutStrategy Additive does not apply"
+// +kubebuilder:validation:XValidation:rule="!has(self.outputMode) || self.outputMode != 'NodeOverlay' || !has(self.changeWindows)",message="outputMode NodeOverlay leaves the offering choice to Karpenter; changeWindows do not apply"
+// +kubebuilder:validation:XValidation:rule="!has(self.mode) || self.mode != 'Shadow' || has(self.nodePoolRef)",message="mode Shadow compares against the NodePool of nodePoolRef, which must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.outputMode) || self.outputMode != 'NodeOverlay' || !has(self.approvalPolicy) || self.approvalPolicy != 'Manual'",message="outputMode NodeOverlay leaves the offering choice to Karpenter; approvalPolicy Manual does not apply"
+// +kubebuilder:validation:XValidation:rule="!has(self.emergencyScoreFloor) || has(self.changeWindows)",message="emergencyScoreFloor requires changeWindows"
+// +kubebuilder:validation:XValidation:rule="!has(self.requirementMode) || self.requirementMode != 'Attributes' || !(has(self.nodePoolRef) || (has(self.outputMode) && self.outputMode == 'NodeOverlay'))",message="requirementMode Attributes cannot be used with nodePoolRef or outputMode NodeOverlay"
+// +kubebuilder:validation:XValidation:rule="!has(self.nodeClassTemplate) || (has(self.subnetSelectorTags) && size(self.subnetSelectorTags) > 0 && has(self.securityGroupSelectorTags) && size(self.securityGroupSelectorTags) > 0)",message="subnetSelectorTags and securityGroupSelectorTags are required with nodeClassTemplate"
 type LeftoverNodePoolSpec struct {
 	// AWS region (e.g. us-east-1)
 	// +kubebuilder:validation:MinLength=1
@@ -130,10 +152,43 @@ type LeftoverNodePoolSpec struct {
 	// GPU instance families filter (e.g. g4dn, g5, p4). Empty = implementation defined discovery.
 	Families []string `json:"families,omitempty"`
 
+	// Discover candidate instance types by attributes, as EC2 Fleet
+	// InstanceRequirements, instead of listing every GPU type in the region.
+	// Placement scores are then requested for the requirements as a whole.
+	// families and minGPUs still filter the result.
+	// +optional
+	InstanceRequirements *InstanceRequirements `json:"instanceRequirements,omitempty"`
+
 	// Exact EC2NodeClass name (exclusive with nodeClassSelector)
 	NodeClassName string `json:"nodeClassName,omitempty"`
-	// Label selector for EC2NodeClass (must match exactly one)
+	// Label selector for EC2NodeClass. When several match, the class is picked per
+	// instance type by its compatible.gpu.devplatforms.io/<family or type> labels.
 	NodeClassSelector map[string]string `json:"nodeClassSelector,omitempty"`
+	// Template of an EC2NodeClass the controller creates and owns (exclusive with
+	// nodeClassName and nodeClassSelector). Subnet and security group selector
+	// terms come from subnetSelectorTags and securityGroupSelectorTags.
+	// +optional
+	NodeClassTemplate *NodeClassTemplate `json:"nodeClassTemplate,omitempty"`
+	// Kind of node class the names and selectors refer to and NodePools
+	// reference. Defaults to karpenter.k8s.aws EC2NodeClass; use
+	// eks.amazonaws.com NodeClass on EKS Auto Mode clusters.
+	// +optional
+	NodeClassRef *NodeClassReference `json:"nodeClassRef,omitempty"`
+	// Existing NodePool to adopt instead of creating leftover-<name>. Leftover
+	// then maintains only its capacity-type, instance-type and zone
+	// requirements; the rest of the NodePool stays with its owner.
+	// +optional
+	NodePoolRef *NodePoolReference `json:"nodePoolRef,omitempty"`
+	// Take ownership of the NodePool requirements when another field manager
+	// changed them. When false, such conflicts leave the NodePool untouched
+	// and set Ready=False.
+	// +kubebuilder:default=true
+	// +optional
+	ForceOwnership *bool `json:"forceOwnership,omitempty"`
+	// Per-family node classes. The first mapping matching a candidate instance
+	// type decides its EC2NodeClass; unmatched types use the class above.
+	// +optional
+	NodeClassMappings []NodeClassMapping `json:"nodeClassMappings,omitempty"`
 
 	// Minimum GPUs per instance type considered.
 	// +kubebuilder:default=1
@@ -141,9 +196,15 @@ type LeftoverNodePoolSpec struct {
 	MinGPUs int `json:"minGPUs,omitempty"`
 
 	// Target pod count used in scoring heuristics.
+	// With spec.demand it is the floor of the derived target count.
 	// +kubebuilder:default=2
 	TargetCount int32 `json:"targetCount,omitempty"`
 
+	// Demand derives the placement score target capacity from workload demand
+	// instead of the static targetCount.
+	// +optional
+	Demand *DemandSource `json:"demand,omitempty"`
+
 	// Minimum acceptable spot score (0..10). If none meet, fallback logic applies.
 	// +kubebuilder:default=5
 	// +kubebuilder:validation:Minimum=0
@@ -165,9 +226,9 @@ type LeftoverNodePoolSpec struct {
 	// +kubebuilder:validation:Enum=spot;on-demand
 	CapacityType string `json:"capacityType,omitempty"`
 
-	// Optional subnet selector tags (passed through to EC2NodeClass or used for extra logic)
+	// Subnet selector tags for the EC2NodeClass rendered from nodeClassTemplate
 	SubnetSelectorTags map[string]string `json:"subnetSelectorTags,omitempty"`
-	// Optional SG selector tags
+	// Security group selector tags for the EC2NodeClass rendered from nodeClassTemplate
 	SecurityGroupSelectorTags map[string]string `json:"securityGroupSelectorTags,omitempty"`
 
 	// Karpenter disruption budgets (nodes percent/absolute; stored as single budget entry)
@@ -190,6 +251,644 @@ type LeftoverNodePoolSpec struct {
 	// If true and no spot choice meets MinSpotScore, fallback to on-demand.
 	// +kubebuilder:default=true
 	OnDemandFallback bool `json:"onDemandFallback,omitempty"`
+
+	// Spot price product description to price offerings with. By default it is
+	// derived from the EC2NodeClass amiFamily, AMI alias or AMI platform.
+	// +kubebuilder:validation:Enum="Linux/UNIX";"SUSE Linux";"Red Hat Enterprise Linux";"Windows"
+	// +optional
+	ProductDescription string `json:"productDescription,omitempty"`
+
+	// Highest acceptable Spot interruption-frequency bucket (Spot Instance Advisor ranges).
+	// Instance types missing from the interruption dataset are assumed to be in the operator's default bucket.
+	// +kubebuilder:validation:Enum="<5%";"5-10%";"10-15%";"15-20%";">20%"
+	// +optional
+	MaxInterruptionRate string `json:"maxInterruptionRate,omitempty"`
+
+	// How candidate offerings are ranked. Defaults to the lowest current price.
+	// +optional
+	Strategy *SelectionStrategy `json:"strategy,omitempty"`
+
+	// How a changed selection reaches the NodePool. Defaults to InPlace.
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// Windows in which a changed selection may be applied. Outside them the
+	// new selection is recorded in status.pendingSelection and the NodePool
+	// keeps the current one. Changes are always allowed when empty.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	ChangeWindows []TimeWindow `json:"changeWindows,omitempty"`
+
+	// Outside change windows, a changed selection is still applied when the
+	// placement score of the current offering drops below this floor.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	EmergencyScoreFloor int32 `json:"emergencyScoreFloor,omitempty"`
+
+	// Automatic (default) applies selection changes as they are computed.
+	// Manual records a changed selection in status.pendingSelection and
+	// applies it only once the gpu.devplatforms.io/approve-selection
+	// annotation carries its hash. The first selection, made while no
+	// offering is applied yet, is applied without approval.
+	// +kubebuilder:default=Automatic
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	// +optional
+	ApprovalPolicy string `json:"approvalPolicy,omitempty"`
+
+	// How long a pending selection can be approved (e.g. "24h") with
+	// approvalPolicy Manual. An expired selection is requested again with a
+	// new hash.
+	// +kubebuilder:default="24h"
+	// +optional
+	ApprovalTimeout string `json:"approvalTimeout,omitempty"`
+
+	// Overrides applied while a schedule's window is open, e.g. on-demand
+	// capacity during business hours. When windows overlap, later schedules
+	// win field by field.
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Schedules []CapacitySchedule `json:"schedules,omitempty"`
+
+	// Managed (default) applies selections to the NodePool. Shadow computes
+	// them every cycle without writing any Karpenter object and compares them
+	// in status.shadow with the nodes of the spec.nodePoolRef NodePool.
+	// +kubebuilder:default=Managed
+	// +kubebuilder:validation:Enum=Managed;Shadow
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// Paused freezes the pool: no AWS calls and no NodePool writes. Status
+	// is still updated.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Pin forces an offering, bypassing selection, change windows and
+	// diversification until it expires. The best candidate is still
+	// evaluated and compared in status.pin.
+	// +optional
+	Pin *Pin `json:"pin,omitempty"`
+
+	// How the selection reaches Karpenter. Pinned (default) restricts the
+	// NodePool to the selected instance type and zone. NodeOverlay allows every
+	// candidate instance type in any zone and publishes Karpenter NodeOverlays
+	// with Leftover's effective price per offering, leaving the choice to
+	// Karpenter; it needs a Karpenter serving NodeOverlays.
+	// +kubebuilder:default=Pinned
+	// +kubebuilder:validation:Enum=Pinned;NodeOverlay
+	// +optional
+	OutputMode string `json:"outputMode,omitempty"`
+
+	// How the NodePool requires the selected instance types. InstanceType
+	// (default) lists their names. Attributes requires their GPU name, count
+	// and memory, instance family and generation instead, so Karpenter may use
+	// other sizes of the same shape when the selected one is exhausted.
+	// +kubebuilder:default=InstanceType
+	// +kubebuilder:validation:Enum=InstanceType;Attributes
+	// +optional
+	RequirementMode string `json:"requirementMode,omitempty"`
+
+	// Spread the capacity over several NodePools, one per selected offering,
+	// instead of a single NodePool.
+	// +optional
+	Diversification *Diversification `json:"diversification,omitempty"`
+}
+
+// Output modes
+const (
+	OutputPinned      = "Pinned"
+	OutputNodeOverlay = "NodeOverlay"
+)
+
+// Requirement modes
+const (
+	RequirementsInstanceType = "InstanceType"
+	RequirementsAttributes   = "Attributes"
+)
+
+// Demand source types
+const (
+	DemandPendingPods = "PendingPods"
+	DemandPrometheus  = "Prometheus"
+)
+
+// DemandSource estimates the number of nodes the pool's workloads need. The
+// estimate, clamped to [targetCount, maxTargetCount], is the target capacity
+// placement scores are requested for.
+// +kubebuilder:validation:XValidation:rule="(self.type == 'Prometheus') == has(self.query)",message="query is required with type Prometheus and only allowed there"
+// +kubebuilder:validation:XValidation:rule="self.type == 'PendingPods' || !has(self.gpusPerNode)",message="gpusPerNode only applies to type PendingPods"
+type DemandSource struct {
+	// PendingPods sums the GPU requests of unschedulable pods whose node
+	// selector and tolerations admit the pool's labels and taints. Prometheus
+	// runs query against the endpoint configured with --prometheus-url.
+	// +kubebuilder:validation:Enum=PendingPods;Prometheus
+	Type string `json:"type"`
+
+	// GPUs per node used to convert pending GPU requests into nodes.
+	// Defaults to minGPUs.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	GPUsPerNode int32 `json:"gpusPerNode,omitempty"`
+
+	// PromQL query returning the number of nodes needed as a scalar or a
+	// single-sample vector, e.g. ceil(sum(queued_gpu_jobs) / 4).
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Query string `json:"query,omitempty"`
+
+	// Upper bound of the derived target count. Unbounded by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxTargetCount int32 `json:"maxTargetCount,omitempty"`
+}
+
+// DemandStatus reports the last demand estimate.
+type DemandStatus struct {
+	// Demand source type.
+	Source string `json:"source"`
+	// Nodes the source estimated.
+	Nodes int32 `json:"nodes"`
+	// Target capacity placement scores were requested for.
+	TargetCount int32 `json:"targetCount"`
+	// Error of the last estimate; targetCount was used instead.
+	// +optional
+	Error string `json:"error,omitempty"`
+	// Time the estimate last changed.
+	LastEstimateTime metav1.Time `json:"lastEstimateTime"`
+}
+
+// Diversification selects the best offerings in rank order and renders one
+// weighted NodePool per offering. Offerings that drop out of the selection are
+// drained like BlueGreen NodePools and deleted once empty.
+// +kubebuilder:validation:XValidation:rule="!has(self.allocation) || size(self.allocation) == self.pools",message="allocation needs one entry per pool"
+type Diversification struct {
+	// Number of NodePools (distinct offerings) to spread over.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	Pools int `json:"pools"`
+
+	// Relative share of limits per pool in rank order, e.g. [50, 30, 20].
+	// Equal shares by default.
+	// +optional
+	Allocation []int32 `json:"allocation,omitempty"`
+
+	// Total resource limits split over the pools by allocation, e.g.
+	// {"cpu": "512", "nvidia.com/gpu": "32"}. Each pool gets its share
+	// rounded up. Without limits the pools are only weighted.
+	// +optional
+	Limits corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// TimeWindow is a recurring window, e.g. one in which selection changes are
+// applied.
+type TimeWindow struct {
+	// Cron schedule of the window starts: minute, hour, day of month, month
+	// and day of week, e.g. "0 22 * * MON-FRI". Macros like @daily work too.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// How long each window stays open, e.g. "4h".
+	// +kubebuilder:validation:MinLength=1
+	Duration string `json:"duration"`
+
+	// IANA time zone the schedule is evaluated in, e.g. "Europe/Berlin".
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Modes
+const (
+	ModeManaged = "Managed"
+	ModeShadow  = "Shadow"
+)
+
+// ShadowStatus compares the selection of a Shadow pool with the nodes the
+// target NodePool actually runs.
+type ShadowStatus struct {
+	// Offerings selection would apply, best first.
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+	Zones         []string `json:"zones,omitempty"`
+	PriceUSD      string   `json:"priceUSD,omitempty"`
+	Score         int32    `json:"score"`
+	// NodePool compared against.
+	NodePoolName string `json:"nodePoolName"`
+	// Offerings the NodePool's nodes run on.
+	ActualOfferings []ShadowOffering `json:"actualOfferings,omitempty"`
+	// GPUs of the NodePool's nodes.
+	ActualGPUs int64 `json:"actualGPUs"`
+	// Nodes of the best selected offering providing as many GPUs.
+	ShadowNodes int32 `json:"shadowNodes"`
+	// Hourly cost of the NodePool's priced nodes and of the shadow nodes.
+	ActualHourlyUSD string `json:"actualHourlyUSD"`
+	ShadowHourlyUSD string `json:"shadowHourlyUSD"`
+	// Nodes without a known price; they are left out of both costs.
+	// +optional
+	UnpricedNodes int32 `json:"unpricedNodes,omitempty"`
+	// Savings accumulated since Since: the difference of the hourly costs
+	// over time. Negative when the selection would have cost more.
+	EstimatedSavingsUSD string `json:"estimatedSavingsUSD"`
+	// Start of the accumulation.
+	Since metav1.Time `json:"since"`
+	// Time of the last comparison.
+	LastEvaluationTime metav1.Time `json:"lastEvaluationTime"`
+}
+
+// ShadowOffering is an offering of the target NodePool's nodes.
+type ShadowOffering struct {
+	InstanceType string `json:"instanceType"`
+	Zone         string `json:"zone"`
+	CapacityType string `json:"capacityType"`
+	Nodes        int32  `json:"nodes"`
+	// Hourly price per node; empty when unknown.
+	// +optional
+	PriceUSD string `json:"priceUSD,omitempty"`
+}
+
+// Pin is a manually forced offering.
+type Pin struct {
+	// +kubebuilder:validation:MinLength=1
+	InstanceType string `json:"instanceType"`
+	// +kubebuilder:validation:MinLength=1
+	Zone string `json:"zone"`
+	// Capacity type of the pinned offering. Defaults to spec.capacityType.
+	// +kubebuilder:validation:Enum=spot;on-demand
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+	// When the pin stops applying; it never expires if unset.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// PinStatus compares the pinned offering with the best available candidate.
+type PinStatus struct {
+	// Whether the pin is applied; false once it expired.
+	Active       bool   `json:"active"`
+	InstanceType string `json:"instanceType"`
+	Zone         string `json:"zone"`
+	// Current Spot price and placement score of the pinned offering.
+	// +optional
+	PriceUSD string `json:"priceUSD,omitempty"`
+	Score    int32  `json:"score"`
+	// Best candidate selection would pick instead.
+	// +optional
+	BestInstanceType string `json:"bestInstanceType,omitempty"`
+	// +optional
+	BestZone string `json:"bestZone,omitempty"`
+	// +optional
+	BestPriceUSD string `json:"bestPriceUSD,omitempty"`
+	// +optional
+	BestScore int32 `json:"bestScore,omitempty"`
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// CapacitySchedule overrides parts of the spec while its window is open.
+// +kubebuilder:validation:XValidation:rule="has(self.capacityType) || has(self.minSpotScore) || has(self.strategy) || has(self.limits)",message="a schedule needs at least one override"
+type CapacitySchedule struct {
+	// Name identifies the schedule in status.activeSchedules.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	TimeWindow `json:",inline"`
+
+	// Capacity type while the window is open.
+	// +kubebuilder:validation:Enum=spot;on-demand
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+
+	// Minimum acceptable spot score while the window is open.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	MinSpotScore *int32 `json:"minSpotScore,omitempty"`
+
+	// Ranking strategy while the window is open; replaces spec.strategy as a whole.
+	// +optional
+	Strategy *SelectionStrategy `json:"strategy,omitempty"`
+
+	// NodePool resource limits while the window is open, e.g. {"cpu": "0"}
+	// to stop launching nodes. With spec.diversification they replace
+	// diversification.limits and are split over the pools.
+	// +optional
+	Limits corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// Pending selection reasons
+const (
+	PendingOutsideChangeWindow = "OutsideChangeWindow"
+	PendingAwaitingApproval    = "AwaitingApproval"
+)
+
+// Approval policies
+const (
+	ApprovalAutomatic = "Automatic"
+	ApprovalManual    = "Manual"
+)
+
+// AnnotationApproveSelection approves the pending selection whose hash it
+// carries.
+const AnnotationApproveSelection = "gpu.devplatforms.io/approve-selection"
+
+// PendingSelection is a selection that was computed but not applied yet.
+type PendingSelection struct {
+	InstanceTypes []string `json:"instanceTypes"`
+	Zones         []string `json:"zones"`
+	PriceUSD      string   `json:"priceUSD"`
+	Score         int32    `json:"score"`
+	// Why the selection is held back, e.g. OutsideChangeWindow.
+	Reason string `json:"reason"`
+	// When this selection was first computed.
+	Since metav1.Time `json:"since"`
+	// When the next change window opens.
+	// +optional
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+	// Applied offering the selection would replace, priced and scored now.
+	// +optional
+	CurrentPriceUSD string `json:"currentPriceUSD,omitempty"`
+	// +optional
+	CurrentScore int32 `json:"currentScore,omitempty"`
+	// Value of the gpu.devplatforms.io/approve-selection annotation that
+	// approves this selection, with reason AwaitingApproval.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// When an unapproved selection is requested again.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// Rollout strategy types
+const (
+	RolloutInPlace   = "InPlace"
+	RolloutAdditive  = "Additive"
+	RolloutBlueGreen = "BlueGreen"
+)
+
+// RolloutStrategy controls how nodes of a previous selection are replaced.
+type RolloutStrategy struct {
+	// InPlace replaces the NodePool requirements, so Karpenter drifts existing
+	// nodes. Additive keeps previously selected offerings in the requirements
+	// while they still have nodes, up to maxAge. BlueGreen creates a NodePool
+	// per selection and drains the previous one (limits set to zero) until its
+	// nodes are gone, then deletes it.
+	// +kubebuilder:default=InPlace
+	// +kubebuilder:validation:Enum=InPlace;Additive;BlueGreen
+	Type string `json:"type,omitempty"`
+
+	// How long Additive keeps a previous offering in the requirements
+	// (e.g. "24h").
+	// +kubebuilder:default="24h"
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+// Rollout phases
+const (
+	RolloutPhaseStable      = "Stable"
+	RolloutPhaseProgressing = "Progressing"
+)
+
+// RolloutStatus reports nodes of previous selections that are still running.
+type RolloutStatus struct {
+	// Rollout strategy in effect.
+	Strategy string `json:"strategy"`
+	// Progressing while previous offerings are retained or NodePools drain; Stable otherwise.
+	Phase string `json:"phase"`
+	// Previous offerings kept in the NodePool requirements (Additive).
+	RetainedOfferings []RetainedOffering `json:"retainedOfferings,omitempty"`
+	// Previous NodePools waiting for their nodes to go before deletion.
+	DrainingNodePools []DrainingNodePool `json:"drainingNodePools,omitempty"`
+}
+
+// RetainedOffering is a previously selected offering kept in the requirements.
+type RetainedOffering struct {
+	InstanceType string `json:"instanceType"`
+	Zone         string `json:"zone"`
+	// When the selection moved away from the offering.
+	Since metav1.Time `json:"since"`
+	// Nodes of the offering still in the NodePool.
+	Nodes int32 `json:"nodes"`
+}
+
+// DrainingNodePool is a NodePool of a previous selection that no longer
+// launches nodes.
+type DrainingNodePool struct {
+	Name  string      `json:"name"`
+	Since metav1.Time `json:"since"`
+	Nodes int32       `json:"nodes"`
+}
+
+// InstanceRequirements mirrors the accelerator, vCPU and memory attributes of
+// EC2 InstanceRequirements. Field values follow the EC2 API.
+type InstanceRequirements struct {
+	// Accelerator types; defaults to [gpu]. Only types reporting GPUs become candidates.
+	// +kubebuilder:validation:items:Enum=gpu;fpga;inference
+	AcceleratorTypes []string `json:"acceleratorTypes,omitempty"`
+	// Accelerator manufacturers, e.g. nvidia, amd, amazon-web-services.
+	AcceleratorManufacturers []string `json:"acceleratorManufacturers,omitempty"`
+	// Accelerator names, e.g. a10g, t4, a100, h100.
+	AcceleratorNames []string `json:"acceleratorNames,omitempty"`
+	// Accelerators per instance.
+	AcceleratorCount *IntRange `json:"acceleratorCount,omitempty"`
+	// Total accelerator memory per instance in MiB.
+	AcceleratorTotalMemoryMiB *IntRange `json:"acceleratorTotalMemoryMiB,omitempty"`
+	// vCPUs per instance.
+	VCPUCount *IntRange `json:"vCpuCount,omitempty"`
+	// Memory per instance in MiB.
+	MemoryMiB *IntRange `json:"memoryMiB,omitempty"`
+	// Instance types to exclude; * wildcards are allowed, e.g. "g4ad.*".
+	// +kubebuilder:validation:MaxItems=400
+	ExcludedInstanceTypes []string `json:"excludedInstanceTypes,omitempty"`
+}
+
+// IntRange is an inclusive range; either bound may be omitted.
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.max) || self.min <= self.max",message="min must not exceed max"
+type IntRange struct {
+	// +kubebuilder:validation:Minimum=0
+	Min *int32 `json:"min,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	Max *int32 `json:"max,omitempty"`
+}
+
+// NodePoolReference names an existing Karpenter NodePool.
+type NodePoolReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// Node class groups and kinds.
+const (
+	NodeClassGroupKarpenter = "karpenter.k8s.aws"
+	NodeClassKindEC2        = "EC2NodeClass"
+	NodeClassGroupAutoMode  = "eks.amazonaws.com"
+	NodeClassKindAutoMode   = "NodeClass"
+)
+
+// NodeClassReference is the group and kind of a node class.
+// +kubebuilder:validation:XValidation:rule="(self.group == 'karpenter.k8s.aws') == (self.kind == 'EC2NodeClass')",message="group karpenter.k8s.aws goes with kind EC2NodeClass, eks.amazonaws.com with NodeClass"
+type NodeClassReference struct {
+	// +kubebuilder:default=karpenter.k8s.aws
+	// +kubebuilder:validation:Enum=karpenter.k8s.aws;eks.amazonaws.com
+	Group string `json:"group,omitempty"`
+	// +kubebuilder:default=EC2NodeClass
+	// +kubebuilder:validation:Enum=EC2NodeClass;NodeClass
+	Kind string `json:"kind,omitempty"`
+}
+
+// NodeClassMapping routes instance families or types to an EC2NodeClass.
+// +kubebuilder:validation:XValidation:rule="has(self.nodeClassName) != (has(self.nodeClassSelector) && size(self.nodeClassSelector) > 0)",message="exactly one of nodeClassName or nodeClassSelector must be set"
+type NodeClassMapping struct {
+	// Shell-style patterns matched against the instance type and its family,
+	// e.g. "p5", "inf2", "g6*" or "g6e.12xlarge".
+	// +kubebuilder:validation:MinItems=1
+	Patterns []string `json:"patterns"`
+	// Exact EC2NodeClass name (exclusive with nodeClassSelector)
+	NodeClassName string `json:"nodeClassName,omitempty"`
+	// Label selector for EC2NodeClass; among several matches the class whose
+	// compatible.gpu.devplatforms.io/<family or type> label is "true" is used.
+	NodeClassSelector map[string]string `json:"nodeClassSelector,omitempty"`
+}
+
+// NodeClassTemplate is the subset of the Karpenter EC2NodeClass spec that can
+// be set on a derived node class. Field names match EC2NodeClass v1.
+// +kubebuilder:validation:XValidation:rule="has(self.role) != has(self.instanceProfile)",message="exactly one of role or instanceProfile must be set"
+type NodeClassTemplate struct {
+	// IAM role name for the node instance profile Karpenter manages.
+	Role string `json:"role,omitempty"`
+	// Existing instance profile name (exclusive with role).
+	InstanceProfile string `json:"instanceProfile,omitempty"`
+
+	// AMI family (e.g. AL2023, Bottlerocket, Windows2022, Custom). Optional when an alias selects the AMIs.
+	AMIFamily string `json:"amiFamily,omitempty"`
+	// AMI selector terms, e.g. [{alias: al2023@latest}].
+	// +kubebuilder:validation:MinItems=1
+	AMISelectorTerms []AMISelectorTerm `json:"amiSelectorTerms"`
+
+	BlockDeviceMappings []BlockDeviceMapping `json:"blockDeviceMappings,omitempty"`
+	MetadataOptions     *MetadataOptions     `json:"metadataOptions,omitempty"`
+	UserData            *string              `json:"userData,omitempty"`
+	// Tags applied to instances, volumes and launch templates.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// AMISelectorTerm selects AMIs by alias, ID, name/owner or tags.
+type AMISelectorTerm struct {
+	Alias string            `json:"alias,omitempty"`
+	ID    string            `json:"id,omitempty"`
+	Name  string            `json:"name,omitempty"`
+	Owner string            `json:"owner,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+// BlockDeviceMapping configures one volume of the nodes.
+type BlockDeviceMapping struct {
+	DeviceName string `json:"deviceName,omitempty"`
+	// Marks the volume as the root volume.
+	RootVolume bool         `json:"rootVolume,omitempty"`
+	EBS        *BlockDevice `json:"ebs,omitempty"`
+}
+
+// BlockDevice is an EBS volume.
+type BlockDevice struct {
+	VolumeSize          *resource.Quantity `json:"volumeSize,omitempty"`
+	VolumeType          string             `json:"volumeType,omitempty"`
+	IOPS                *int64             `json:"iops,omitempty"`
+	Throughput          *int64             `json:"throughput,omitempty"`
+	Encrypted           *bool              `json:"encrypted,omitempty"`
+	KMSKeyID            string             `json:"kmsKeyID,omitempty"`
+	DeleteOnTermination *bool              `json:"deleteOnTermination,omitempty"`
+	SnapshotID          string             `json:"snapshotID,omitempty"`
+}
+
+// MetadataOptions configures the instance metadata service.
+type MetadataOptions struct {
+	HTTPEndpoint            string `json:"httpEndpoint,omitempty"`
+	HTTPProtocolIPv6        string `json:"httpProtocolIPv6,omitempty"`
+	HTTPPutResponseHopLimit *int64 `json:"httpPutResponseHopLimit,omitempty"`
+	HTTPTokens              string `json:"httpTokens,omitempty"`
+}
+
+// Strategy types
+const (
+	StrategyLowestPrice   = "LowestPrice"
+	StrategyExpectedPrice = "ExpectedPrice"
+	StrategyWorkloadFit   = "WorkloadFit"
+)
+
+// SelectionStrategy ranks offerings using their price history.
+type SelectionStrategy struct {
+	// LowestPrice ranks by current price; ExpectedPrice ranks by the EWMA forecast over forecastHours.
+	// WorkloadFit packs the GPU, CPU and memory requests of the pool's pending and running pods
+	// onto each candidate instance type and ranks by the current price of the nodes needed.
+	// +kubebuilder:default=LowestPrice
+	// +kubebuilder:validation:Enum=LowestPrice;ExpectedPrice;WorkloadFit
+	Type string `json:"type,omitempty"`
+
+	// Forecast horizon for ExpectedPrice.
+	// +kubebuilder:default=6
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=168
+	ForecastHours int `json:"forecastHours,omitempty"`
+
+	// Percent added to the ranking price per 1.0 of volatility (coefficient of variation).
+	// E.g. 50 makes a pool whose price varies by 20% rank 10% more expensive.
+	// +kubebuilder:validation:Minimum=0
+	VolatilityPenaltyPercent int `json:"volatilityPenaltyPercent,omitempty"`
+
+	// Percent added to the ranking price per interruption-frequency bucket above "<5%".
+	// E.g. 10 makes a "10-15%" pool rank 20% more expensive.
+	// +kubebuilder:validation:Minimum=0
+	InterruptionPenaltyPercent int `json:"interruptionPenaltyPercent,omitempty"`
+
+	// Percent added to the NodeOverlay price per placement score point below 10.
+	// E.g. 5 makes an offering scored 6 20% more expensive. Pinned output
+	// filters by minSpotScore instead.
+	// +kubebuilder:validation:Minimum=0
+	PlacementPenaltyPercent int `json:"placementPenaltyPercent,omitempty"`
+}
+
+// WorkloadFitStatus explains the WorkloadFit selection.
+type WorkloadFitStatus struct {
+	// GPU-requesting pods packed: unschedulable ones and those running on the pool's nodes.
+	Pods int32 `json:"pods"`
+	// GPUs the pods request.
+	GPUs int64 `json:"gpus"`
+	// Nodes of the selected instance type the pods pack onto.
+	Nodes int32 `json:"nodes"`
+	// Hourly price of those nodes (nodes x ranking price).
+	FleetPriceUSD string `json:"fleetPriceUSD"`
+	// Requested GPUs per GPU of the packed nodes.
+	GPUUtilizationPercent int32 `json:"gpuUtilizationPercent"`
+	// Instance types skipped because a pod exceeds a whole node.
+	// +optional
+	UnfitInstanceTypes int32 `json:"unfitInstanceTypes,omitempty"`
+	// Cheapest instance type (by fleet price) that was not selected, for comparison.
+	// +optional
+	RunnerUp *WorkloadFitCandidate `json:"runnerUp,omitempty"`
+}
+
+// WorkloadFitCandidate is the packing of the workload onto another instance type.
+type WorkloadFitCandidate struct {
+	InstanceType  string `json:"instanceType"`
+	Nodes         int32  `json:"nodes"`
+	FleetPriceUSD string `json:"fleetPriceUSD"`
+}
+
+// PriceStats summarizes the price history of the selected offering.
+type PriceStats struct {
+	// Hours of history the statistics are based on.
+	WindowHours int    `json:"windowHours"`
+	MeanUSD     string `json:"meanUSD"`
+	// Coefficient of variation (stddev / mean) over the window.
+	Volatility string `json:"volatility"`
+	// Relative price change over the last 24h.
+	TrendPerDay string `json:"trendPerDay"`
+	// EWMA forecast over the strategy's horizon.
+	ForecastUSD string `json:"forecastUSD"`
+	// Price the offering was ranked by (forecast or current price plus volatility penalty).
+	EffectivePriceUSD string `json:"effectivePriceUSD"`
 }
 
 // LeftoverNodePoolStatus defines the observed state of LeftoverNodePool.
@@ -200,6 +899,51 @@ type LeftoverNodePoolStatus struct {
 	LastPriceUSD          string             `json:"lastPriceUSD,omitempty"`
 	LastScore             int                `json:"lastScore,omitempty"`
 	LastSyncTime          metav1.Time        `json:"lastSyncTime,omitempty"`
+	// Name of the SpotMarket publishing the market data for spec.region.
+	SpotMarket string `json:"spotMarket,omitempty"`
+	// NodePool carrying the selection: leftover-<name> or spec.nodePoolRef.
+	NodePoolName string `json:"nodePoolName,omitempty"`
+	// EC2NodeClass referenced by the generated NodePool.
+	NodeClassName string `json:"nodeClassName,omitempty"`
+	// Karpenter API version (v1 or v1beta1) the NodePool is rendered for.
+	KarpenterAPIVersion string `json:"karpenterAPIVersion,omitempty"`
+	// Product description the offerings were priced with, e.g. "Linux/UNIX".
+	ProductDescription string `json:"productDescription,omitempty"`
+	// Interruption-frequency bucket of the selected instance type, e.g. "<5%".
+	// Suffixed with " (assumed)" when the interruption dataset had no entry for it.
+	InterruptionRate string `json:"interruptionRate,omitempty"`
+	// Price history statistics of the selected offering.
+	PriceStats *PriceStats `json:"priceStats,omitempty"`
+	// Progress of replacing nodes of previous selections.
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// NodeOverlays published with outputMode NodeOverlay.
+	NodeOverlays int32 `json:"nodeOverlays,omitempty"`
+	// NodePools carrying the selection with spec.diversification, in rank order.
+	DiversifiedNodePools []DiversifiedNodePool `json:"diversifiedNodePools,omitempty"`
+	// Target capacity derived from spec.demand.
+	Demand *DemandStatus `json:"demand,omitempty"`
+	// Comparison of the selection with the target NodePool in mode Shadow.
+	Shadow *ShadowStatus `json:"shadow,omitempty"`
+	// Pinned offering compared with the best candidate, while spec.pin is set.
+	Pin *PinStatus `json:"pin,omitempty"`
+	// Names of the spec.schedules whose windows are open, in spec order.
+	ActiveSchedules []string `json:"activeSchedules,omitempty"`
+	// Selection computed but held back, e.g. outside spec.changeWindows.
+	PendingSelection *PendingSelection `json:"pendingSelection,omitempty"`
+	// Packing of the workload onto the selected instance type with strategy WorkloadFit.
+	WorkloadFit *WorkloadFitStatus `json:"workloadFit,omitempty"`
+}
+
+// DiversifiedNodePool is one NodePool of a diversified selection.
+type DiversifiedNodePool struct {
+	Name          string `json:"name"`
+	InstanceType  string `json:"instanceType"`
+	Zone          string `json:"zone"`
+	NodeClassName string `json:"nodeClassName"`
+	// Karpenter weight; higher ranked pools are preferred.
+	Weight int32 `json:"weight"`
+	// Limits of the pool, its share of spec.diversification.limits.
+	Limits corev1.ResourceList `json:"limits,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,131 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SpotMarket condition types
+const (
+	// ConditionFresh is True while the published snapshot is younger than two refresh intervals.
+	ConditionFresh = "Fresh"
+)
+
+// SpotMarketSpec defines which market data is published for a region.
+type SpotMarketSpec struct {
+	// AWS region (e.g. us-east-1)
+	// +kubebuilder:validation:MinLength=1
+	Region string `json:"region"`
+
+	// GPU instance families filter (e.g. g4dn, g5, p4). Empty = all GPU families.
+	Families []string `json:"families,omitempty"`
+
+	// Refresh interval in minutes.
+	// +kubebuilder:default=15
+	// +kubebuilder:validation:Minimum=1
+	RefreshMinutes int `json:"refreshMinutes,omitempty"`
+
+	// Target instance count used when requesting placement scores.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	TargetCount int32 `json:"targetCount,omitempty"`
+
+	// Spot price product description the offerings are priced with.
+	// +kubebuilder:default="Linux/UNIX"
+	// +kubebuilder:validation:Enum="Linux/UNIX";"SUSE Linux";"Red Hat Enterprise Linux";"Windows"
+	ProductDescription string `json:"productDescription,omitempty"`
+}
+
+// SpotOffering is the latest market data for one (instance type, zone) pair.
+type SpotOffering struct {
+	InstanceType string `json:"instanceType"`
+	Zone         string `json:"zone"`
+	ZoneID       string `json:"zoneID,omitempty"`
+
+	// Latest Spot price (USD/hour).
+	PriceUSD string `json:"priceUSD"`
+	// Time the Spot price was published by EC2.
+	QuoteTime metav1.Time `json:"quoteTime"`
+	// Age of the quote, in seconds, when the snapshot was taken.
+	QuoteAgeSeconds int64 `json:"quoteAgeSeconds"`
+	// Spot placement score (1..10, 0 if unknown).
+	Score int32 `json:"score"`
+	// On-demand price (USD/hour) for the same instance type, if known.
+	OnDemandPriceUSD string `json:"onDemandPriceUSD,omitempty"`
+
+	VCPUs           int32  `json:"vcpus,omitempty"`
+	MemoryMiB       int32  `json:"memoryMiB,omitempty"`
+	GPUCount        int32  `json:"gpuCount,omitempty"`
+	GPUMemoryMiB    int32  `json:"gpuMemoryMiB,omitempty"`
+	GPUName         string `json:"gpuName,omitempty"`
+	GPUManufacturer string `json:"gpuManufacturer,omitempty"`
+	// Interruption-frequency bucket from the interruption dataset (e.g. "<5%"), empty when unknown.
+	InterruptionRate string `json:"interruptionRate,omitempty"`
+	// Coefficient of variation of the price over the retained history.
+	Volatility string `json:"volatility,omitempty"`
+	// Relative price change over the last 24h.
+	TrendPerDay string `json:"trendPerDay,omitempty"`
+	// EWMA forecast over the next 6 hours.
+	ForecastUSD string `json:"forecastUSD,omitempty"`
+}
+
+// SpotMarketStatus is the latest published market snapshot.
+type SpotMarketStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Time the snapshot was taken.
+	SnapshotTime metav1.Time `json:"snapshotTime,omitempty"`
+	// Time the next refresh is due.
+	NextRefreshTime metav1.Time `json:"nextRefreshTime,omitempty"`
+	// Publish time of the oldest quote in the snapshot.
+	OldestQuoteTime metav1.Time `json:"oldestQuoteTime,omitempty"`
+	// Number of instance types scored from stale data because the placement score budget was exhausted.
+	StaleScoreTypes int `json:"staleScoreTypes,omitempty"`
+	// Offerings ordered by Spot price, cheapest first.
+	Offerings []SpotOffering `json:"offerings,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=spotmarkets,scope=Cluster,shortName=spm,categories=gpu;leftover
+// +kubebuilder:printcolumn:name="Region",type=string,JSONPath=`.spec.region`
+// +kubebuilder:printcolumn:name="Fresh",type=string,JSONPath=`.status.conditions[?(@.type=="Fresh")].status`
+// +kubebuilder:printcolumn:name="Snapshot",type=date,JSONPath=`.status.snapshotTime`
+// +kubebuilder:printcolumn:name="Cheapest",type=string,JSONPath=`.status.offerings[0].instanceType`
+// +kubebuilder:printcolumn:name="Price",type=string,JSONPath=`.status.offerings[0].priceUSD`
+
+// SpotMarket is the Schema for the spotmarkets API
+type SpotMarket struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SpotMarketSpec   `json:"spec"`
+	Status SpotMarketStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SpotMarketList contains a list of SpotMarket
+type SpotMarketList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SpotMarket `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SpotMarket{}, &SpotMarketList{})
+}
@@ -21,10 +21,302 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AMISelectorTerm) DeepCopyInto(out *AMISelectorTerm) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AMISelectorTerm.
+func (in *AMISelectorTerm) DeepCopy() *AMISelectorTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(AMISelectorTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDevice) DeepCopyInto(out *BlockDevice) {
+	*out = *in
+	if in.VolumeSize != nil {
+		in, out := &in.VolumeSize, &out.VolumeSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.IOPS != nil {
+		in, out := &in.IOPS, &out.IOPS
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Throughput != nil {
+		in, out := &in.Throughput, &out.Throughput
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Encrypted != nil {
+		in, out := &in.Encrypted, &out.Encrypted
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeleteOnTermination != nil {
+		in, out := &in.DeleteOnTermination, &out.DeleteOnTermination
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDevice.
+func (in *BlockDevice) DeepCopy() *BlockDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDeviceMapping) DeepCopyInto(out *BlockDeviceMapping) {
+	*out = *in
+	if in.EBS != nil {
+		in, out := &in.EBS, &out.EBS
+		*out = new(BlockDevice)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDeviceMapping.
+func (in *BlockDeviceMapping) DeepCopy() *BlockDeviceMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDeviceMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedule) DeepCopyInto(out *CapacitySchedule) {
+	*out = *in
+	out.TimeWindow = in.TimeWindow
+	if in.MinSpotScore != nil {
+		in, out := &in.MinSpotScore, &out.MinSpotScore
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(SelectionStrategy)
+		**out = **in
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedule.
+func (in *CapacitySchedule) DeepCopy() *CapacitySchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DemandSource) DeepCopyInto(out *DemandSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DemandSource.
+func (in *DemandSource) DeepCopy() *DemandSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DemandSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DemandStatus) DeepCopyInto(out *DemandStatus) {
+	*out = *in
+	in.LastEstimateTime.DeepCopyInto(&out.LastEstimateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DemandStatus.
+func (in *DemandStatus) DeepCopy() *DemandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DemandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Diversification) DeepCopyInto(out *Diversification) {
+	*out = *in
+	if in.Allocation != nil {
+		in, out := &in.Allocation, &out.Allocation
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Diversification.
+func (in *Diversification) DeepCopy() *Diversification {
+	if in == nil {
+		return nil
+	}
+	out := new(Diversification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiversifiedNodePool) DeepCopyInto(out *DiversifiedNodePool) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiversifiedNodePool.
+func (in *DiversifiedNodePool) DeepCopy() *DiversifiedNodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(DiversifiedNodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainingNodePool) DeepCopyInto(out *DrainingNodePool) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainingNodePool.
+func (in *DrainingNodePool) DeepCopy() *DrainingNodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainingNodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceRequirements) DeepCopyInto(out *InstanceRequirements) {
+	*out = *in
+	if in.AcceleratorTypes != nil {
+		in, out := &in.AcceleratorTypes, &out.AcceleratorTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AcceleratorManufacturers != nil {
+		in, out := &in.AcceleratorManufacturers, &out.AcceleratorManufacturers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AcceleratorNames != nil {
+		in, out := &in.AcceleratorNames, &out.AcceleratorNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AcceleratorCount != nil {
+		in, out := &in.AcceleratorCount, &out.AcceleratorCount
+		*out = new(IntRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AcceleratorTotalMemoryMiB != nil {
+		in, out := &in.AcceleratorTotalMemoryMiB, &out.AcceleratorTotalMemoryMiB
+		*out = new(IntRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VCPUCount != nil {
+		in, out := &in.VCPUCount, &out.VCPUCount
+		*out = new(IntRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryMiB != nil {
+		in, out := &in.MemoryMiB, &out.MemoryMiB
+		*out = new(IntRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludedInstanceTypes != nil {
+		in, out := &in.ExcludedInstanceTypes, &out.ExcludedInstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceRequirements.
+func (in *InstanceRequirements) DeepCopy() *InstanceRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntRange) DeepCopyInto(out *IntRange) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntRange.
+func (in *IntRange) DeepCopy() *IntRange {
+	if in == nil {
+		return nil
+	}
+	out := new(IntRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LeftoverNodePool) DeepCopyInto(out *LeftoverNodePool) {
 	*out = *in
@@ -92,6 +384,11 @@ func (in *LeftoverNodePoolSpec) DeepCopyInto(out *LeftoverNodePoolSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.InstanceRequirements != nil {
+		in, out := &in.InstanceRequirements, &out.InstanceRequirements
+		*out = new(InstanceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NodeClassSelector != nil {
 		in, out := &in.NodeClassSelector, &out.NodeClassSelector
 		*out = make(map[string]string, len(*in))
@@ -99,6 +396,38 @@ func (in *LeftoverNodePoolSpec) DeepCopyInto(out *LeftoverNodePoolSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.NodeClassTemplate != nil {
+		in, out := &in.NodeClassTemplate, &out.NodeClassTemplate
+		*out = new(NodeClassTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeClassRef != nil {
+		in, out := &in.NodeClassRef, &out.NodeClassRef
+		*out = new(NodeClassReference)
+		**out = **in
+	}
+	if in.NodePoolRef != nil {
+		in, out := &in.NodePoolRef, &out.NodePoolRef
+		*out = new(NodePoolReference)
+		**out = **in
+	}
+	if in.ForceOwnership != nil {
+		in, out := &in.ForceOwnership, &out.ForceOwnership
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NodeClassMappings != nil {
+		in, out := &in.NodeClassMappings, &out.NodeClassMappings
+		*out = make([]NodeClassMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Demand != nil {
+		in, out := &in.Demand, &out.Demand
+		*out = new(DemandSource)
+		**out = **in
+	}
 	if in.SubnetSelectorTags != nil {
 		in, out := &in.SubnetSelectorTags, &out.SubnetSelectorTags
 		*out = make(map[string]string, len(*in))
@@ -125,6 +454,38 @@ func (in *LeftoverNodePoolSpec) DeepCopyInto(out *LeftoverNodePoolSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(SelectionStrategy)
+		**out = **in
+	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(RolloutStrategy)
+		**out = **in
+	}
+	if in.ChangeWindows != nil {
+		in, out := &in.ChangeWindows, &out.ChangeWindows
+		*out = make([]TimeWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]CapacitySchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pin != nil {
+		in, out := &in.Pin, &out.Pin
+		*out = new(Pin)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diversification != nil {
+		in, out := &in.Diversification, &out.Diversification
+		*out = new(Diversification)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeftoverNodePoolSpec.
@@ -142,7 +503,7 @@ func (in *LeftoverNodePoolStatus) DeepCopyInto(out *LeftoverNodePoolStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -158,6 +519,53 @@ func (in *LeftoverNodePoolStatus) DeepCopyInto(out *LeftoverNodePoolStatus) {
 		copy(*out, *in)
 	}
 	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.PriceStats != nil {
+		in, out := &in.PriceStats, &out.PriceStats
+		*out = new(PriceStats)
+		**out = **in
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiversifiedNodePools != nil {
+		in, out := &in.DiversifiedNodePools, &out.DiversifiedNodePools
+		*out = make([]DiversifiedNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Demand != nil {
+		in, out := &in.Demand, &out.Demand
+		*out = new(DemandStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shadow != nil {
+		in, out := &in.Shadow, &out.Shadow
+		*out = new(ShadowStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pin != nil {
+		in, out := &in.Pin, &out.Pin
+		*out = new(PinStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActiveSchedules != nil {
+		in, out := &in.ActiveSchedules, &out.ActiveSchedules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingSelection != nil {
+		in, out := &in.PendingSelection, &out.PendingSelection
+		*out = new(PendingSelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadFit != nil {
+		in, out := &in.WorkloadFit, &out.WorkloadFit
+		*out = new(WorkloadFitStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeftoverNodePoolStatus.
@@ -169,3 +577,512 @@ func (in *LeftoverNodePoolStatus) DeepCopy() *LeftoverNodePoolStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataOptions) DeepCopyInto(out *MetadataOptions) {
+	*out = *in
+	if in.HTTPPutResponseHopLimit != nil {
+		in, out := &in.HTTPPutResponseHopLimit, &out.HTTPPutResponseHopLimit
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataOptions.
+func (in *MetadataOptions) DeepCopy() *MetadataOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeClassMapping) DeepCopyInto(out *NodeClassMapping) {
+	*out = *in
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeClassSelector != nil {
+		in, out := &in.NodeClassSelector, &out.NodeClassSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClassMapping.
+func (in *NodeClassMapping) DeepCopy() *NodeClassMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeClassMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeClassReference) DeepCopyInto(out *NodeClassReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClassReference.
+func (in *NodeClassReference) DeepCopy() *NodeClassReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeClassReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeClassTemplate) DeepCopyInto(out *NodeClassTemplate) {
+	*out = *in
+	if in.AMISelectorTerms != nil {
+		in, out := &in.AMISelectorTerms, &out.AMISelectorTerms
+		*out = make([]AMISelectorTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BlockDeviceMappings != nil {
+		in, out := &in.BlockDeviceMappings, &out.BlockDeviceMappings
+		*out = make([]BlockDeviceMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MetadataOptions != nil {
+		in, out := &in.MetadataOptions, &out.MetadataOptions
+		*out = new(MetadataOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserData != nil {
+		in, out := &in.UserData, &out.UserData
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClassTemplate.
+func (in *NodeClassTemplate) DeepCopy() *NodeClassTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeClassTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolReference) DeepCopyInto(out *NodePoolReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolReference.
+func (in *NodePoolReference) DeepCopy() *NodePoolReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingSelection) DeepCopyInto(out *PendingSelection) {
+	*out = *in
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Since.DeepCopyInto(&out.Since)
+	if in.NextWindow != nil {
+		in, out := &in.NextWindow, &out.NextWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingSelection.
+func (in *PendingSelection) DeepCopy() *PendingSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Pin) DeepCopyInto(out *Pin) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pin.
+func (in *Pin) DeepCopy() *Pin {
+	if in == nil {
+		return nil
+	}
+	out := new(Pin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinStatus) DeepCopyInto(out *PinStatus) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinStatus.
+func (in *PinStatus) DeepCopy() *PinStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PinStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriceStats) DeepCopyInto(out *PriceStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriceStats.
+func (in *PriceStats) DeepCopy() *PriceStats {
+	if in == nil {
+		return nil
+	}
+	out := new(PriceStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetainedOffering) DeepCopyInto(out *RetainedOffering) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainedOffering.
+func (in *RetainedOffering) DeepCopy() *RetainedOffering {
+	if in == nil {
+		return nil
+	}
+	out := new(RetainedOffering)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	if in.RetainedOfferings != nil {
+		in, out := &in.RetainedOfferings, &out.RetainedOfferings
+		*out = make([]RetainedOffering, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DrainingNodePools != nil {
+		in, out := &in.DrainingNodePools, &out.DrainingNodePools
+		*out = make([]DrainingNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectionStrategy) DeepCopyInto(out *SelectionStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectionStrategy.
+func (in *SelectionStrategy) DeepCopy() *SelectionStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectionStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowOffering) DeepCopyInto(out *ShadowOffering) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowOffering.
+func (in *ShadowOffering) DeepCopy() *ShadowOffering {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowOffering)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowStatus) DeepCopyInto(out *ShadowStatus) {
+	*out = *in
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ActualOfferings != nil {
+		in, out := &in.ActualOfferings, &out.ActualOfferings
+		*out = make([]ShadowOffering, len(*in))
+		copy(*out, *in)
+	}
+	in.Since.DeepCopyInto(&out.Since)
+	in.LastEvaluationTime.DeepCopyInto(&out.LastEvaluationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowStatus.
+func (in *ShadowStatus) DeepCopy() *ShadowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotMarket) DeepCopyInto(out *SpotMarket) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotMarket.
+func (in *SpotMarket) DeepCopy() *SpotMarket {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotMarket)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SpotMarket) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotMarketList) DeepCopyInto(out *SpotMarketList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SpotMarket, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotMarketList.
+func (in *SpotMarketList) DeepCopy() *SpotMarketList {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotMarketList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SpotMarketList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotMarketSpec) DeepCopyInto(out *SpotMarketSpec) {
+	*out = *in
+	if in.Families != nil {
+		in, out := &in.Families, &out.Families
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotMarketSpec.
+func (in *SpotMarketSpec) DeepCopy() *SpotMarketSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotMarketSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotMarketStatus) DeepCopyInto(out *SpotMarketStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.SnapshotTime.DeepCopyInto(&out.SnapshotTime)
+	in.NextRefreshTime.DeepCopyInto(&out.NextRefreshTime)
+	in.OldestQuoteTime.DeepCopyInto(&out.OldestQuoteTime)
+	if in.Offerings != nil {
+		in, out := &in.Offerings, &out.Offerings
+		*out = make([]SpotOffering, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotMarketStatus.
+func (in *SpotMarketStatus) DeepCopy() *SpotMarketStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotMarketStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotOffering) DeepCopyInto(out *SpotOffering) {
+	*out = *in
+	in.QuoteTime.DeepCopyInto(&out.QuoteTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotOffering.
+func (in *SpotOffering) DeepCopy() *SpotOffering {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotOffering)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWindow.
+func (in *TimeWindow) DeepCopy() *TimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadFitCandidate) DeepCopyInto(out *WorkloadFitCandidate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadFitCandidate.
+func (in *WorkloadFitCandidate) DeepCopy() *WorkloadFitCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadFitCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadFitStatus) DeepCopyInto(out *WorkloadFitStatus) {
+	*out = *in
+	if in.RunnerUp != nil {
+		in, out := &in.RunnerUp, &out.RunnerUp
+		*out = new(WorkloadFitCandidate)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadFitStatus.
+func (in *WorkloadFitStatus) DeepCopy() *WorkloadFitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadFitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,13 +20,20 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"strings"
+	"time"
+	// Embed the time zone database so spec.changeWindows time zones resolve
+	// regardless of the base image.
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -36,7 +43,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/advisor"
+	"github.com/devplatformsolutions/leftover/internal/awsx"
 	"github.com/devplatformsolutions/leftover/internal/controller"
+	"github.com/devplatformsolutions/leftover/internal/demand"
+	"github.com/devplatformsolutions/leftover/internal/history"
+	"github.com/devplatformsolutions/leftover/internal/karpenterx"
 	webhookv1alpha1 "github.com/devplatformsolutions/leftover/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
@@ -63,6 +75,16 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	var ec2Endpoints string
+	var historyNamespace string
+	var historyRetention time.Duration
+	var interruptionFile, interruptionCM, interruptionDefaultLabel string
+	var prometheusURL string
+	awsOpts := awsx.Options{
+		CacheTTLs:         awsx.DefaultCacheTTLs(),
+		ScoreBudget:       awsx.DefaultScoreBudget(),
+		MarketScoreBudget: awsx.DefaultMarketScoreBudget(),
+	}
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -80,6 +102,45 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&awsOpts.CacheTTLs.InstanceTypes, "aws-cache-instance-types-ttl", awsOpts.CacheTTLs.InstanceTypes,
+		"How long EC2 instance type metadata is cached per region.")
+	flag.DurationVar(&awsOpts.CacheTTLs.Zones, "aws-cache-zones-ttl", awsOpts.CacheTTLs.Zones,
+		"How long availability zone name to ID mappings are cached per region.")
+	flag.DurationVar(&awsOpts.CacheTTLs.Prices, "aws-cache-prices-ttl", awsOpts.CacheTTLs.Prices,
+		"How long Spot price quotes are cached per region.")
+	flag.DurationVar(&awsOpts.CacheTTLs.Scores, "aws-cache-scores-ttl", awsOpts.CacheTTLs.Scores,
+		"How long Spot placement scores are cached per region.")
+	flag.DurationVar(&awsOpts.CacheTTLs.OnDemandPrices, "aws-cache-on-demand-prices-ttl", awsOpts.CacheTTLs.OnDemandPrices,
+		"How long on-demand prices from the Price List API are cached per region.")
+	flag.StringVar(&ec2Endpoints, "aws-ec2-endpoint", "",
+		"Override the EC2 endpoint: a single URL for all regions, or region=URL pairs separated by commas.")
+	flag.BoolVar(&awsOpts.Endpoints.UseFIPS, "aws-use-fips-endpoint", false,
+		"If set, use FIPS endpoints for AWS API calls.")
+	flag.BoolVar(&awsOpts.Endpoints.UseDualStack, "aws-use-dualstack-endpoint", false,
+		"If set, use dual-stack (IPv4 and IPv6) endpoints for AWS API calls.")
+	flag.Float64Var(&awsOpts.ScoreBudget.RequestsPerHour, "placement-score-requests-per-hour",
+		awsOpts.ScoreBudget.RequestsPerHour,
+		"Account-wide budget for GetSpotPlacementScores calls. Use 0 to disable the budget.")
+	flag.IntVar(&awsOpts.ScoreBudget.Burst, "placement-score-burst", awsOpts.ScoreBudget.Burst,
+		"Number of GetSpotPlacementScores calls that may be made back to back.")
+	flag.Float64Var(&awsOpts.MarketScoreBudget.RequestsPerHour, "spotmarket-placement-score-requests-per-hour",
+		awsOpts.MarketScoreBudget.RequestsPerHour,
+		"Account-wide budget for the GetSpotPlacementScores calls of SpotMarket snapshots. Use 0 to disable the budget.")
+	flag.IntVar(&awsOpts.MarketScoreBudget.Burst, "spotmarket-placement-score-burst", awsOpts.MarketScoreBudget.Burst,
+		"Number of SpotMarket GetSpotPlacementScores calls that may be made back to back.")
+	flag.StringVar(&historyNamespace, "price-history-namespace", os.Getenv("POD_NAMESPACE"),
+		"Namespace of the ConfigMaps persisting Spot price history. Empty keeps history in memory only.")
+	flag.DurationVar(&historyRetention, "price-history-retention", history.DefaultRetention,
+		"How much Spot price history to keep per offering.")
+	flag.StringVar(&interruptionFile, "interruption-data-file", "",
+		"Path to a Spot Instance Advisor dataset (spot-advisor-data.json).")
+	flag.StringVar(&interruptionCM, "interruption-data-configmap", "",
+		"ConfigMap holding a Spot Instance Advisor dataset under key "+advisor.ConfigMapKey+
+			", as name or namespace/name (namespace defaults to the price history namespace).")
+	flag.StringVar(&interruptionDefaultLabel, "interruption-default-rate", ">20%",
+		"Interruption-frequency bucket assumed for instance types missing from the dataset.")
+	flag.StringVar(&prometheusURL, "prometheus-url", "",
+		"Prometheus HTTP API endpoint for spec.demand queries, e.g. http://prometheus.monitoring:9090.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -88,6 +149,35 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	var err error
+	if awsOpts.Endpoints.EC2, err = awsx.ParseEndpointOverrides(ec2Endpoints); err != nil {
+		setupLog.Error(err, "invalid --aws-ec2-endpoint")
+		os.Exit(1)
+	}
+
+	interruptionDefault, ok := advisor.RangeByLabel(interruptionDefaultLabel)
+	if !ok {
+		setupLog.Error(nil, "invalid --interruption-default-rate", "value", interruptionDefaultLabel)
+		os.Exit(1)
+	}
+	prometheus, err := demand.NewPrometheusClient(prometheusURL)
+	if err != nil {
+		setupLog.Error(err, "invalid --prometheus-url")
+		os.Exit(1)
+	}
+
+	var interruptionConfigMap types.NamespacedName
+	if interruptionCM != "" {
+		if interruptionFile != "" {
+			setupLog.Error(nil, "--interruption-data-file and --interruption-data-configmap are mutually exclusive")
+			os.Exit(1)
+		}
+		interruptionConfigMap = types.NamespacedName{Namespace: historyNamespace, Name: interruptionCM}
+		if ns, name, found := strings.Cut(interruptionCM, "/"); found {
+			interruptionConfigMap = types.NamespacedName{Namespace: ns, Name: name}
+		}
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -179,13 +269,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	awsFactory := awsx.NewFactory(awsOpts)
+	interruptions := advisor.NewProvider(advisor.Config{
+		File:      interruptionFile,
+		ConfigMap: interruptionConfigMap,
+		Reader:    mgr.GetAPIReader(),
+		Default:   interruptionDefault,
+	})
+	priceHistory := history.NewStore(mgr.GetClient(), mgr.GetAPIReader(), historyNamespace, historyRetention)
+
+	disc, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	karpenter := karpenterx.NewDetector(disc)
+	if api, err := karpenter.API(); err != nil {
+		// Reconciles report Ready=False and retry detection until Karpenter is installed.
+		setupLog.Error(err, "Karpenter API detection failed")
+	} else {
+		setupLog.Info("detected Karpenter API", "version", api.Version, "nodeOverlays", api.NodeOverlays)
+	}
+
 	if err := (&controller.LeftoverNodePoolReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		AWSFactory:    awsFactory,
+		History:       priceHistory,
+		Interruptions: interruptions,
+		Karpenter:     karpenter,
+		Recorder:      mgr.GetEventRecorderFor("leftover"),
+		Prometheus:    prometheus,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LeftoverNodePool")
 		os.Exit(1)
 	}
+	if err := (&controller.SpotMarketReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		AWSFactory:    awsFactory,
+		History:       priceHistory,
+		Interruptions: interruptions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SpotMarket")
+		os.Exit(1)
+	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err := webhookv1alpha1.SetupLeftoverNodePoolWebhookWithManager(mgr); err != nil {
@@ -0,0 +1,218 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package advisor loads Spot interruption-frequency data in the format
+// published by the Spot Instance Advisor (spot-advisor-data.json). The data is
+// read from a file or ConfigMap rather than fetched, so it also works offline.
+package advisor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ConfigMapKey is the key read from a ConfigMap holding the dataset.
+const ConfigMapKey = "spot-advisor-data.json"
+
+// Operating systems used as keys in the dataset.
+const (
+	OSLinux   = "Linux"
+	OSWindows = "Windows"
+)
+
+// OSForProduct maps a Spot price product description (e.g. "Windows",
+// "Red Hat Enterprise Linux") to the operating system key of the dataset.
+func OSForProduct(product string) string {
+	if strings.HasPrefix(product, "Windows") {
+		return OSWindows
+	}
+	return OSLinux
+}
+
+// DefaultRefresh is how often the dataset is re-read from its source.
+const DefaultRefresh = 10 * time.Minute
+
+// Range is one interruption-frequency bucket, e.g. "<5%".
+type Range struct {
+	Index int    `json:"index"`
+	Label string `json:"label"`
+	// Max is the upper bound of the bucket in percent.
+	Max int `json:"max"`
+}
+
+// DefaultRanges are the buckets used by the Spot Instance Advisor.
+var DefaultRanges = []Range{
+	{Index: 0, Label: "<5%", Max: 5},
+	{Index: 1, Label: "5-10%", Max: 11},
+	{Index: 2, Label: "10-15%", Max: 16},
+	{Index: 3, Label: "15-20%", Max: 22},
+	{Index: 4, Label: ">20%", Max: 100},
+}
+
+// RangeByLabel returns the default bucket with the given label.
+func RangeByLabel(label string) (Range, bool) {
+	for _, r := range DefaultRanges {
+		if r.Label == label {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
+
+type advice struct {
+	// R is the index of the interruption-frequency range.
+	R int `json:"r"`
+	// S is the savings over On-Demand in percent.
+	S int `json:"s"`
+}
+
+// Dataset is a parsed Spot Instance Advisor document.
+type Dataset struct {
+	Ranges []Range `json:"ranges"`
+	// SpotAdvisor maps region -> operating system ("Linux", "Windows") -> instance type -> advice.
+	SpotAdvisor map[string]map[string]map[string]advice `json:"spot_advisor"`
+}
+
+// Parse decodes a spot-advisor-data.json document.
+func Parse(raw []byte) (*Dataset, error) {
+	var d Dataset
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return nil, fmt.Errorf("parsing interruption data: %w", err)
+	}
+	if len(d.SpotAdvisor) == 0 {
+		return nil, fmt.Errorf("parsing interruption data: no spot_advisor entries")
+	}
+	if len(d.Ranges) == 0 {
+		d.Ranges = DefaultRanges
+	}
+	sort.Slice(d.Ranges, func(i, j int) bool { return d.Ranges[i].Index < d.Ranges[j].Index })
+	return &d, nil
+}
+
+// Lookup returns the interruption range of instanceType in region for os.
+func (d *Dataset) Lookup(region, os, instanceType string) (Range, bool) {
+	a, ok := d.SpotAdvisor[region][os][instanceType]
+	if !ok {
+		return Range{}, false
+	}
+	for _, r := range d.Ranges {
+		if r.Index == a.R {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
+
+// Config selects where a Provider reads its dataset from. At most one of File
+// and ConfigMap should be set; with neither, every rate is unknown.
+type Config struct {
+	File      string
+	ConfigMap types.NamespacedName
+	// Reader reads the ConfigMap; an uncached reader avoids watching ConfigMaps.
+	Reader client.Reader
+	// Default is the range assumed for instance types missing from the dataset.
+	Default Range
+	Refresh time.Duration
+}
+
+// Provider serves lookups from a dataset that is re-read periodically. When a
+// reload fails the previous dataset stays in use.
+type Provider struct {
+	cfg Config
+
+	mu       sync.Mutex
+	data     *Dataset
+	loadedAt time.Time
+}
+
+func NewProvider(cfg Config) *Provider {
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = DefaultRefresh
+	}
+	return &Provider{cfg: cfg}
+}
+
+// Configured reports whether a dataset source was set.
+func (p *Provider) Configured() bool {
+	return p != nil && (p.cfg.File != "" || p.cfg.ConfigMap.Name != "")
+}
+
+// Rate returns the interruption range of instanceType, or the configured
+// default when the dataset has no entry. known reports whether the dataset had
+// one. Without a loaded dataset the rate is unknown: the zero Range, whose
+// Label is empty.
+func (p *Provider) Rate(ctx context.Context, region, os, instanceType string) (r Range, known bool) {
+	if !p.Configured() {
+		return Range{}, false
+	}
+	d := p.dataset(ctx)
+	if d == nil {
+		return Range{}, false
+	}
+	if r, ok := d.Lookup(region, os, instanceType); ok {
+		return r, true
+	}
+	return p.cfg.Default, false
+}
+
+func (p *Provider) dataset(ctx context.Context) *Dataset {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.data != nil && time.Since(p.loadedAt) < p.cfg.Refresh {
+		return p.data
+	}
+	d, err := p.load(ctx)
+	// Retry a failed load only after the refresh interval, like a successful one.
+	p.loadedAt = time.Now()
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "loading interruption data failed; keeping previous data")
+		return p.data
+	}
+	p.data = d
+	return d
+}
+
+func (p *Provider) load(ctx context.Context) (*Dataset, error) {
+	if p.cfg.File != "" {
+		raw, err := os.ReadFile(p.cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		return Parse(raw)
+	}
+	var cm corev1.ConfigMap
+	if err := p.cfg.Reader.Get(ctx, p.cfg.ConfigMap, &cm); err != nil {
+		return nil, err
+	}
+	if raw, ok := cm.Data[ConfigMapKey]; ok {
+		return Parse([]byte(raw))
+	}
+	if raw, ok := cm.BinaryData[ConfigMapKey]; ok {
+		return Parse(raw)
+	}
+	return nil, fmt.Errorf("configmap %s has no %q key", p.cfg.ConfigMap, ConfigMapKey)
+}
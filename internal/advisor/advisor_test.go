@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package advisor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sample = `{
+  "ranges": [
+    {"index": 0, "label": "<5%", "dots": 0, "max": 5},
+    {"index": 1, "label": "5-10%", "dots": 1, "max": 11},
+    {"index": 4, "label": ">20%", "dots": 4, "max": 100}
+  ],
+  "spot_advisor": {
+    "us-east-1": {
+      "Linux": {
+        "g5.xlarge": {"s": 70, "r": 0},
+        "g4dn.xlarge": {"s": 65, "r": 4}
+      }
+    }
+  }
+}`
+
+func TestLookup(t *testing.T) {
+	d, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := d.Lookup("us-east-1", "Linux", "g4dn.xlarge"); !ok || r.Label != ">20%" {
+		t.Fatalf("got %+v, %v", r, ok)
+	}
+	if _, ok := d.Lookup("us-east-1", "Windows", "g5.xlarge"); ok {
+		t.Fatal("unexpected Windows entry")
+	}
+}
+
+func TestProviderDefaultsMissingData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigMapKey)
+	if err := os.WriteFile(path, []byte(sample), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	def, _ := RangeByLabel("10-15%")
+	p := NewProvider(Config{File: path, Default: def})
+
+	if r, known := p.Rate(context.Background(), "us-east-1", "Linux", "g5.xlarge"); !known || r.Label != "<5%" {
+		t.Fatalf("got %+v, %v", r, known)
+	}
+	if r, known := p.Rate(context.Background(), "us-west-2", "Linux", "g5.xlarge"); known || r.Label != "10-15%" {
+		t.Fatalf("missing entry should use the default, got %+v, %v", r, known)
+	}
+}
+
+func TestProviderWithoutDatasetIsUnknown(t *testing.T) {
+	def, _ := RangeByLabel(">20%")
+	cases := []struct {
+		name string
+		cfg  Config
+	}{
+		{"not configured", Config{Default: def}},
+		{"load failed", Config{File: filepath.Join(t.TempDir(), "missing.json"), Default: def}},
+	}
+	for _, tc := range cases {
+		p := NewProvider(tc.cfg)
+		if r, known := p.Rate(context.Background(), "us-east-1", "Linux", "g5.xlarge"); known || r != (Range{}) {
+			t.Errorf("%s: got %+v, %v, want the unknown zero Range", tc.name, r, known)
+		}
+	}
+}
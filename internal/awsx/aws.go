@@ -2,33 +2,142 @@ package awsx
 
 import (
 	"context"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 )
 
-type Factory struct{}
+// Options configures clients created by a Factory.
+type Options struct {
+	CacheTTLs CacheTTLs
+	Endpoints EndpointOptions
+	// ScoreBudget is shared by all regions.
+	ScoreBudget ScoreBudget
+	// MarketScoreBudget is a separate budget, shared by all regions, for the
+	// placement scores of SpotMarket snapshots (see Client.ForMarket), so
+	// snapshots cannot starve LeftoverNodePools.
+	MarketScoreBudget ScoreBudget
+}
+
+// Factory hands out one long-lived Client per region so that market data
+// cached by a client is shared by every reconcile targeting that region.
+type Factory struct {
+	opts              Options
+	scoreBudget       *rate.Limiter
+	marketScoreBudget *rate.Limiter
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	// inits coalesces concurrent client creation per region.
+	inits singleflight.Group
+}
 
-func NewFactory() *Factory { return &Factory{} }
+func NewFactory(opts Options) *Factory {
+	return &Factory{
+		opts:              opts,
+		scoreBudget:       opts.ScoreBudget.limiter(),
+		marketScoreBudget: opts.MarketScoreBudget.limiter(),
+		clients:           make(map[string]*Client),
+	}
+}
 
 type Client struct {
 	EC2 *ec2.Client
-	// add Pricing if you later need OD price
+	// Pricing is nil where the partition has no Price List API.
+	Pricing *pricing.Client
+
+	partition         Partition
+	ttls              CacheTTLs
+	cache             *ttlCache
+	scoreBudget       *rate.Limiter
+	marketScoreBudget *rate.Limiter
+	lastScores        *lastScores
 }
 
+// ForRegion returns the client of region, creating it on first use. Loading
+// the AWS config of one region does not block callers of the others.
 func (f *Factory) ForRegion(ctx context.Context, region string) (*Client, error) {
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	f.mu.Lock()
+	c, ok := f.clients[region]
+	f.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	v, err, _ := f.inits.Do(region, func() (any, error) {
+		c, err := f.newClient(context.WithoutCancel(ctx), region)
+		if err != nil {
+			return nil, err
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if existing, ok := f.clients[region]; ok {
+			return existing, nil
+		}
+		f.clients[region] = c
+		return c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Client), nil
+}
+
+func (f *Factory) newClient(ctx context.Context, region string) (*Client, error) {
+	loadOpts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if f.opts.Endpoints.UseFIPS {
+		loadOpts = append(loadOpts, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+	if f.opts.Endpoints.UseDualStack {
+		loadOpts = append(loadOpts, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
-		EC2: ec2.NewFromConfig(cfg),
-	}, nil
+	endpoint := f.opts.Endpoints.ec2Endpoint(region)
+	partition := PartitionForRegion(region)
+	var pricingCli *pricing.Client
+	if partition.PricingRegion != "" {
+		pricingCli = pricing.NewFromConfig(cfg, func(o *pricing.Options) {
+			o.Region = partition.PricingRegion
+		})
+	}
+	c := &Client{
+		Pricing: pricingCli,
+		EC2: ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+			if endpoint != "" {
+				o.BaseEndpoint = aws.String(endpoint)
+			}
+		}),
+		partition:         partition,
+		ttls:              f.opts.CacheTTLs,
+		cache:             newTTLCache(),
+		scoreBudget:       f.scoreBudget,
+		marketScoreBudget: f.marketScoreBudget,
+		lastScores:        &lastScores{},
+	}
+	return c, nil
+}
+
+// Partition returns the AWS partition of the client's region.
+func (c *Client) Partition() Partition { return c.partition }
+
+// ForMarket returns a client sharing c's caches that requests placement
+// scores within the SpotMarket budget instead of the LeftoverNodePool one.
+func (c *Client) ForMarket() *Client {
+	m := *c
+	m.scoreBudget = c.marketScoreBudget
+	return &m
 }
 
 type InstanceMeta struct {
@@ -37,6 +146,11 @@ type InstanceMeta struct {
 	MemoryMiB int32
 	GPUCount  int32
 	GPUMemMiB int32
+	// GPUName and GPUManufacturer describe the first GPU model (e.g. "T4", "NVIDIA").
+	GPUName         string
+	GPUManufacturer string
+	// Architectures lists the supported CPU architectures in Kubernetes form ("amd64", "arm64").
+	Architectures []string
 }
 
 type SpotQuote struct {
@@ -48,38 +162,79 @@ type SpotQuote struct {
 
 // ListGPUInstanceTypes returns instance types (filtered by families and min GPUs) and their meta.
 func (c *Client) ListGPUInstanceTypes(ctx context.Context, families []string, minGPUs int) ([]string, map[string]InstanceMeta, error) {
-	p := ec2.NewDescribeInstanceTypesPaginator(c.EC2, &ec2.DescribeInstanceTypesInput{})
+	all, err := cached(ctx, c.cache, cacheKindInstanceTypes, "gpu", c.ttls.InstanceTypes, c.describeGPUInstanceTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+	instances, meta := filterGPUTypes(all, families, minGPUs)
+	return instances, meta, nil
+}
+
+// filterGPUTypes keeps the types in the given families with at least minGPUs.
+func filterGPUTypes(all []InstanceMeta, families []string, minGPUs int) ([]string, map[string]InstanceMeta) {
 	instances := []string{}
 	meta := make(map[string]InstanceMeta)
+	for _, m := range all {
+		if m.GPUCount < int32(minGPUs) || !matchesFamily(m.Type, families) {
+			continue
+		}
+		instances = append(instances, m.Type)
+		meta[m.Type] = m
+	}
+	return instances, meta
+}
+
+// describeGPUInstanceTypes pages through every instance type in the region and keeps those with GPUs.
+func (c *Client) describeGPUInstanceTypes(ctx context.Context) ([]InstanceMeta, error) {
+	p := ec2.NewDescribeInstanceTypesPaginator(c.EC2, &ec2.DescribeInstanceTypesInput{})
+	var out []InstanceMeta
 	for p.HasMorePages() {
 		page, err := p.NextPage(ctx)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		for _, it := range page.InstanceTypes {
-			if it.GpuInfo == nil {
-				continue
-			}
-			var gpuCount int32
-			for _, g := range it.GpuInfo.Gpus {
-				if g.Count != nil {
-					gpuCount += *g.Count
-				}
+			if m, ok := gpuInstanceMeta(it); ok {
+				out = append(out, m)
 			}
-			if gpuCount < int32(minGPUs) || !matchesFamily(string(it.InstanceType), families) {
-				continue
-			}
-			instances = append(instances, string(it.InstanceType))
-			meta[string(it.InstanceType)] = InstanceMeta{
-				Type:      string(it.InstanceType),
-				VCPUs:     *it.VCpuInfo.DefaultCores,
-				MemoryMiB: int32(*it.MemoryInfo.SizeInMiB),
-				GPUCount:  gpuCount,
-				GPUMemMiB: *it.GpuInfo.TotalGpuMemoryInMiB,
+		}
+	}
+	return out, nil
+}
+
+// gpuInstanceMeta converts an instance type with GPUs; ok is false without.
+func gpuInstanceMeta(it types.InstanceTypeInfo) (InstanceMeta, bool) {
+	if it.GpuInfo == nil {
+		return InstanceMeta{}, false
+	}
+	m := InstanceMeta{
+		Type:      string(it.InstanceType),
+		GPUMemMiB: aws.ToInt32(it.GpuInfo.TotalGpuMemoryInMiB),
+	}
+	if it.VCpuInfo != nil {
+		m.VCPUs = aws.ToInt32(it.VCpuInfo.DefaultVCpus)
+	}
+	if it.MemoryInfo != nil {
+		m.MemoryMiB = int32(aws.ToInt64(it.MemoryInfo.SizeInMiB))
+	}
+	if it.ProcessorInfo != nil {
+		for _, a := range it.ProcessorInfo.SupportedArchitectures {
+			switch a {
+			case types.ArchitectureTypeX8664:
+				m.Architectures = append(m.Architectures, "amd64")
+			case types.ArchitectureTypeArm64:
+				m.Architectures = append(m.Architectures, "arm64")
 			}
 		}
 	}
-	return instances, meta, nil
+	for _, g := range it.GpuInfo.Gpus {
+		m.GPUCount += aws.ToInt32(g.Count)
+		if m.GPUName == "" {
+			m.GPUName = aws.ToString(g.Name)
+			m.GPUManufacturer = aws.ToString(g.Manufacturer)
+		}
+	}
+	return m, true
 }
 
 func matchesFamily(instanceType string, families []string) bool {
@@ -94,11 +249,21 @@ func matchesFamily(instanceType string, families []string) bool {
 	return false
 }
 
-// LatestSpotPrices gets last N minutes of quotes and keeps latest per (type, zone).
-func (c *Client) LatestSpotPrices(ctx context.Context, instanceTypes []string, window time.Duration) (map[[2]string]SpotQuote, error) {
+// LatestSpotPrices gets last N minutes of quotes for product (see ProductLinux)
+// and keeps latest per (type, zone). Results are cached per (product, types,
+// window); the returned map must not be mutated.
+func (c *Client) LatestSpotPrices(ctx context.Context, product string, instanceTypes []string, window time.Duration) (map[[2]string]SpotQuote, error) {
 	if window <= 0 {
 		window = 15 * time.Minute
 	}
+	product = normalizeProduct(product)
+	key := product + "/" + window.String() + "/" + typesKey(instanceTypes)
+	return cached(ctx, c.cache, cacheKindPrices, key, c.ttls.Prices, func(ctx context.Context) (map[[2]string]SpotQuote, error) {
+		return c.describeLatestSpotPrices(ctx, product, instanceTypes, window)
+	})
+}
+
+func (c *Client) describeLatestSpotPrices(ctx context.Context, product string, instanceTypes []string, window time.Duration) (map[[2]string]SpotQuote, error) {
 	now := time.Now().UTC()
 	start := now.Add(-window)
 
@@ -114,7 +279,7 @@ func (c *Client) LatestSpotPrices(ctx context.Context, instanceTypes []string, w
 	in := &ec2.DescribeSpotPriceHistoryInput{
 		StartTime:           &start,
 		EndTime:             &now,
-		ProductDescriptions: []string{"Linux/UNIX (Amazon VPC)"},
+		ProductDescriptions: []string{c.partition.ProductDescription(product)},
 	}
 	if len(typeFilters) > 0 {
 		in.InstanceTypes = typeFilters
@@ -157,26 +322,79 @@ func (c *Client) LatestSpotPrices(ctx context.Context, instanceTypes []string, w
 	return latest, nil
 }
 
-// PlacementScores returns a simple AZ -> score map (1..10, 0 if unknown).
-func (c *Client) PlacementScores(ctx context.Context, instanceTypes []string, targetCount int32) (map[string]int32, error) {
-	if targetCount <= 0 {
-		targetCount = 1
+// SpotPriceHistory returns every price change of product since start for the
+// given types, oldest first. It is not cached; callers use it to backfill
+// their own history.
+func (c *Client) SpotPriceHistory(ctx context.Context, product string, instanceTypes []string, start time.Time) ([]SpotQuote, error) {
+	now := time.Now().UTC()
+	in := &ec2.DescribeSpotPriceHistoryInput{
+		StartTime:           &start,
+		EndTime:             &now,
+		ProductDescriptions: []string{c.partition.ProductDescription(product)},
+	}
+	for _, it := range instanceTypes {
+		if it != "" {
+			in.InstanceTypes = append(in.InstanceTypes, types.InstanceType(it))
+		}
+	}
+
+	var out []SpotQuote
+	p := ec2.NewDescribeSpotPriceHistoryPaginator(c.EC2, in)
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, sp := range page.SpotPriceHistory {
+			if sp.InstanceType == "" || sp.AvailabilityZone == nil || sp.Timestamp == nil || sp.SpotPrice == nil {
+				continue
+			}
+			price, err := strconv.ParseFloat(*sp.SpotPrice, 64)
+			if err != nil {
+				continue
+			}
+			out = append(out, SpotQuote{
+				InstanceType: string(sp.InstanceType),
+				Zone:         *sp.AvailabilityZone,
+				PriceUSD:     price,
+				Timestamp:    *sp.Timestamp,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out, nil
+}
+
+// placementScores makes one GetSpotPlacementScores request and returns a simple
+// AZ -> score map (1..10, 0 if unknown). Callers go through TypePlacementScores,
+// which applies caching and the account-wide budget.
+func (c *Client) placementScores(ctx context.Context, instanceTypes []string, targetCount int32) (map[string]int32, error) {
+	in := c.placementScoresInput(targetCount)
+	if len(instanceTypes) > 0 {
+		in.InstanceTypes = instanceTypes
 	}
+	return c.spotPlacementScores(ctx, in)
+}
 
-	in := &ec2.GetSpotPlacementScoresInput{
+func (c *Client) placementScoresInput(targetCount int32) *ec2.GetSpotPlacementScoresInput {
+	return &ec2.GetSpotPlacementScoresInput{
 		SingleAvailabilityZone: aws.Bool(true),                    // AZ-level scores
 		TargetCapacity:         aws.Int32(targetCount),            // number of instances
 		TargetCapacityUnitType: types.TargetCapacityUnitTypeUnits, // interpret TargetCapacity as "units" (instances)
 		RegionNames:            []string{c.EC2.Options().Region},  // limit to this client’s region
 	}
-	if len(instanceTypes) > 0 {
-		in.InstanceTypes = instanceTypes
-	}
+}
 
+// spotPlacementScores pages through a GetSpotPlacementScores request.
+func (c *Client) spotPlacementScores(ctx context.Context, in *ec2.GetSpotPlacementScoresInput) (map[string]int32, error) {
 	p := ec2.NewGetSpotPlacementScoresPaginator(c.EC2, in)
 
 	scores := make(map[string]int32)
 	for p.HasMorePages() {
+		// Every page is a call against the quota.
+		if !c.scoreBudget.Allow() {
+			return nil, errScoreBudget
+		}
 		out, err := p.NextPage(ctx)
 		if err != nil {
 			return nil, err
@@ -200,7 +418,13 @@ func (c *Client) PlacementScores(ctx context.Context, instanceTypes []string, ta
 	return scores, nil
 }
 
+// AZNameToID maps availability zone names (us-east-1a) to zone IDs (use1-az1).
+// The returned map is cached and must not be mutated.
 func (c *Client) AZNameToID(ctx context.Context) (map[string]string, error) {
+	return cached(ctx, c.cache, cacheKindZones, "available", c.ttls.Zones, c.describeAvailabilityZones)
+}
+
+func (c *Client) describeAvailabilityZones(ctx context.Context) (map[string]string, error) {
 	out, err := c.EC2.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
 		AllAvailabilityZones: aws.Bool(false),
 		Filters: []types.Filter{
@@ -218,3 +442,10 @@ func (c *Client) AZNameToID(ctx context.Context) (map[string]string, error) {
 	}
 	return m, nil
 }
+
+// typesKey builds an order-independent cache key for a list of instance types.
+func typesKey(instanceTypes []string) string {
+	sorted := append([]string(nil), instanceTypes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/devplatformsolutions/leftover/internal/metrics"
+)
+
+// Cache kinds, used as key prefixes and metric labels.
+const (
+	cacheKindInstanceTypes = "instance_types"
+	cacheKindZones         = "zones"
+	cacheKindPrices        = "prices"
+	cacheKindScores        = "scores"
+	cacheKindOnDemand      = "on_demand_prices"
+	cacheKindImages        = "images"
+)
+
+// fetchTimeout bounds a coalesced EC2 call, which runs detached from the
+// context of the caller that started it.
+const fetchTimeout = 2 * time.Minute
+
+// CacheTTLs controls how long each kind of market data is reused before EC2 is queried again.
+// A zero or negative TTL disables caching for that kind (concurrent calls are still coalesced).
+type CacheTTLs struct {
+	InstanceTypes time.Duration
+	Zones         time.Duration
+	Prices        time.Duration
+	Scores        time.Duration
+	// OnDemandPrices applies to the Price List API, whose data changes rarely.
+	OnDemandPrices time.Duration
+}
+
+// DefaultCacheTTLs returns TTLs suited to how quickly each kind of data changes.
+func DefaultCacheTTLs() CacheTTLs {
+	return CacheTTLs{
+		InstanceTypes:  6 * time.Hour,
+		Zones:          6 * time.Hour,
+		Prices:         5 * time.Minute,
+		Scores:         10 * time.Minute,
+		OnDemandPrices: 24 * time.Hour,
+	}
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// ttlCache is a per-region cache of EC2 responses. Concurrent misses for the
+// same key share one in-flight call. Expired entries are evicted whenever a
+// value is stored.
+type ttlCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	flights singleflight.Group
+}
+
+func newTTLCache() *ttlCache {
+	return &ttlCache{entries: make(map[string]cacheEntry)}
+}
+
+// lookup returns the value stored under (kind, key) and whether it is still
+// fresh.
+func (c *ttlCache) lookup(kind, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[kind+"/"+key]
+	if !ok || !time.Now().Before(e.expires) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// store stores v under k until ttl has passed and evicts expired entries.
+func (c *ttlCache) store(k string, v any, ttl time.Duration) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	if ttl > 0 {
+		c.entries[k] = cacheEntry{value: v, expires: now.Add(ttl)}
+	}
+}
+
+// cached returns the value stored under (kind, key) if it is younger than ttl,
+// otherwise calls fetch once for all concurrent callers and stores the result.
+// fetch runs without the caller's cancellation, bounded by fetchTimeout, so a
+// caller giving up does not fail the others; each caller still returns when
+// its own ctx is done. Returned values are shared between callers and must
+// not be mutated.
+func cached[T any](ctx context.Context, c *ttlCache, kind, key string, ttl time.Duration, fetch func(context.Context) (T, error)) (T, error) {
+	var zero T
+	k := kind + "/" + key
+	if v, fresh := c.lookup(kind, key); fresh {
+		metrics.AWSCacheRequests.WithLabelValues(kind, "hit").Inc()
+		return v.(T), nil
+	}
+
+	fetched := false
+	ch := c.flights.DoChan(k, func() (any, error) {
+		fetched = true
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fetchTimeout)
+		defer cancel()
+		v, err := fetch(fctx)
+		if err != nil {
+			return nil, err
+		}
+		c.store(k, v, ttl)
+		return v, nil
+	})
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	if fetched {
+		metrics.AWSCacheRequests.WithLabelValues(kind, "miss").Inc()
+	} else {
+		metrics.AWSCacheRequests.WithLabelValues(kind, "coalesced").Inc()
+	}
+	if res.Err != nil {
+		return zero, res.Err
+	}
+	return res.Val.(T), nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachedCoalescesConcurrentMisses(t *testing.T) {
+	c := newTTLCache()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make([]int, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = cached(context.Background(), c, cacheKindZones, "k", time.Minute, fetch)
+		}()
+	}
+	// Let every caller join the flight before it completes.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("fetch called %d times, want 1", n)
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("caller %d got %d", i, v)
+		}
+	}
+}
+
+func TestCachedDetachesFetchFromFirstCaller(t *testing.T) {
+	c := newTTLCache()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return 7, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cached(ctx, c, cacheKindZones, "k", time.Minute, fetch)
+		firstErr <- err
+	}()
+	<-started
+	waiter := make(chan int, 1)
+	go func() {
+		v, _ := cached(context.Background(), c, cacheKindZones, "k", time.Minute, fetch)
+		waiter <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The first caller gives up; it returns at once, the shared fetch goes on.
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first caller err = %v, want context.Canceled", err)
+	}
+	close(release)
+	if v := <-waiter; v != 7 {
+		t.Fatalf("waiter got %d, want 7", v)
+	}
+}
+
+func TestCachedTTL(t *testing.T) {
+	cases := []struct {
+		name      string
+		ttl       time.Duration
+		wait      time.Duration
+		wantCalls int32
+	}{
+		{"fresh hit", time.Minute, 0, 1},
+		{"expired", 10 * time.Millisecond, 20 * time.Millisecond, 2},
+		{"disabled", 0, 0, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTTLCache()
+			var calls atomic.Int32
+			fetch := func(context.Context) (int32, error) { return calls.Add(1), nil }
+			if _, err := cached(context.Background(), c, cacheKindPrices, "k", tc.ttl, fetch); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(tc.wait)
+			v, err := cached(context.Background(), c, cacheKindPrices, "k", tc.ttl, fetch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tc.wantCalls || calls.Load() != tc.wantCalls {
+				t.Fatalf("got %d after %d calls, want %d", v, calls.Load(), tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCacheEvictsExpiredEntries(t *testing.T) {
+	c := newTTLCache()
+	c.store(cacheKindPrices+"/old", 1, 10*time.Millisecond)
+	c.store(cacheKindPrices+"/kept", 2, time.Minute)
+	time.Sleep(20 * time.Millisecond)
+	c.store(cacheKindPrices+"/new", 3, time.Minute)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[cacheKindPrices+"/old"]; ok || len(c.entries) != 2 {
+		t.Fatalf("entries = %v, want kept and new", c.entries)
+	}
+}
+
+func TestCachedDoesNotStoreErrors(t *testing.T) {
+	c := newTTLCache()
+	boom := errors.New("boom")
+	if _, err := cached(context.Background(), c, cacheKindZones, "k", time.Minute, func(context.Context) (int, error) {
+		return 0, boom
+	}); !errors.Is(err, boom) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, fresh := c.lookup(cacheKindZones, "k"); fresh {
+		t.Fatal("error result was cached")
+	}
+}
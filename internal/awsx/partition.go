@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Partition describes the AWS partition a region belongs to and the defaults
+// that differ between partitions.
+type Partition struct {
+	// ID is the partition identifier (aws, aws-cn, aws-us-gov, ...).
+	ID string
+	// VPCProductDescriptions is true where Spot price history reports VPC
+	// products with an " (Amazon VPC)" suffix, which is only the case in the
+	// commercial partition (a legacy of EC2-Classic).
+	VPCProductDescriptions bool
+	// PricingRegion hosts the Price List API for the partition; empty if it has none.
+	PricingRegion string
+}
+
+var (
+	partitionAWS      = Partition{ID: "aws", VPCProductDescriptions: true, PricingRegion: "us-east-1"}
+	partitionChina    = Partition{ID: "aws-cn", PricingRegion: "cn-northwest-1"}
+	partitionGovCloud = Partition{ID: "aws-us-gov"}
+	partitionISO      = Partition{ID: "aws-iso"}
+	partitionISOB     = Partition{ID: "aws-iso-b"}
+)
+
+// PartitionForRegion returns the partition a region name belongs to.
+func PartitionForRegion(region string) Partition {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return partitionChina
+	case strings.HasPrefix(region, "us-gov-"):
+		return partitionGovCloud
+	case strings.HasPrefix(region, "us-isob-"):
+		return partitionISOB
+	case strings.HasPrefix(region, "us-iso-"):
+		return partitionISO
+	default:
+		return partitionAWS
+	}
+}
+
+// ProductDescription is the Spot price product description for product
+// (e.g. ProductWindows) on instances in a VPC. Empty means ProductLinux.
+func (p Partition) ProductDescription(product string) string {
+	product = normalizeProduct(product)
+	if p.VPCProductDescriptions {
+		return product + " (Amazon VPC)"
+	}
+	return product
+}
+
+// EndpointOptions configures how EC2 endpoints are resolved.
+type EndpointOptions struct {
+	// EC2 overrides the EC2 endpoint URL per region. The key "*" applies to every
+	// region without its own entry (e.g. a local EC2-compatible emulator).
+	EC2 map[string]string
+	// UseFIPS selects FIPS 140-2 endpoints.
+	UseFIPS bool
+	// UseDualStack selects dual-stack (IPv4 + IPv6) endpoints.
+	UseDualStack bool
+}
+
+// ec2Endpoint returns the override for region, if any.
+func (o EndpointOptions) ec2Endpoint(region string) string {
+	if u, ok := o.EC2[region]; ok {
+		return u
+	}
+	return o.EC2["*"]
+}
+
+// regionName matches AWS region names such as us-east-1 or us-isob-east-1.
+var regionName = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// ParseEndpointOverrides parses either a single URL (applied to all regions) or
+// a comma separated list of region=URL pairs. The value is a list only when it
+// starts with a region name and "=", so a single URL may carry a query string.
+func ParseEndpointOverrides(s string) (map[string]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	out := make(map[string]string)
+	if region, _, ok := strings.Cut(s, "="); !ok || !regionName.MatchString(region) {
+		if err := validateEndpointURL(s); err != nil {
+			return nil, err
+		}
+		out["*"] = s
+		return out, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		region, u, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !regionName.MatchString(region) {
+			return nil, fmt.Errorf("endpoint override %q must be region=URL", pair)
+		}
+		if err := validateEndpointURL(u); err != nil {
+			return nil, err
+		}
+		out[region] = u
+	}
+	return out, nil
+}
+
+func validateEndpointURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint URL %q: %w", s, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid endpoint URL %q: expected http(s)://host[:port]", s)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	cases := []struct {
+		region  string
+		id      string
+		pricing string
+	}{
+		{"us-east-1", "aws", "us-east-1"},
+		{"eu-central-1", "aws", "us-east-1"},
+		{"cn-north-1", "aws-cn", "cn-northwest-1"},
+		{"cn-northwest-1", "aws-cn", "cn-northwest-1"},
+		{"us-gov-west-1", "aws-us-gov", ""},
+		{"us-iso-east-1", "aws-iso", ""},
+		{"us-isob-east-1", "aws-iso-b", ""},
+	}
+	for _, tc := range cases {
+		p := PartitionForRegion(tc.region)
+		if p.ID != tc.id || p.PricingRegion != tc.pricing {
+			t.Errorf("%s: got %s (pricing %q), want %s (pricing %q)", tc.region, p.ID, p.PricingRegion, tc.id, tc.pricing)
+		}
+	}
+}
+
+func TestProductDescription(t *testing.T) {
+	cases := []struct {
+		region  string
+		product string
+		want    string
+	}{
+		{"us-east-1", "", "Linux/UNIX (Amazon VPC)"},
+		{"us-east-1", ProductLinux, "Linux/UNIX (Amazon VPC)"},
+		{"us-east-1", ProductWindows, "Windows (Amazon VPC)"},
+		{"cn-north-1", "", "Linux/UNIX"},
+		{"us-gov-west-1", ProductWindows, "Windows"},
+		{"us-isob-east-1", "", "Linux/UNIX"},
+	}
+	for _, tc := range cases {
+		if got := PartitionForRegion(tc.region).ProductDescription(tc.product); got != tc.want {
+			t.Errorf("%s %q: got %q, want %q", tc.region, tc.product, got, tc.want)
+		}
+	}
+}
+
+func TestParseEndpointOverrides(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"empty", " ", nil, false},
+		{"single URL", "http://localhost:4566", map[string]string{"*": "http://localhost:4566"}, false},
+		{"single URL with query", "https://proxy.internal/ec2?target=us-east-1&a=b",
+			map[string]string{"*": "https://proxy.internal/ec2?target=us-east-1&a=b"}, false},
+		{"region pairs", "us-east-1=https://a.example, us-gov-west-1=https://b.example",
+			map[string]string{"us-east-1": "https://a.example", "us-gov-west-1": "https://b.example"}, false},
+		{"pair with query", "eu-west-1=https://a.example/?x=y", map[string]string{"eu-west-1": "https://a.example/?x=y"}, false},
+		{"not a region key", "us-east-1=https://a.example,east=https://b.example", nil, true},
+		{"pair without URL", "us-east-1=https://a.example,eu-west-1", nil, true},
+		{"invalid URL", "us-east-1=localhost", nil, true},
+		{"no scheme", "localhost:4566", nil, true},
+	}
+	for _, tc := range cases {
+		got, err := ParseEndpointOverrides(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEC2EndpointFallsBackToWildcard(t *testing.T) {
+	o := EndpointOptions{EC2: map[string]string{"*": "http://all", "us-east-1": "http://one"}}
+	if got := o.ec2Endpoint("us-east-1"); got != "http://one" {
+		t.Errorf("us-east-1: got %q", got)
+	}
+	if got := o.ec2Endpoint("eu-west-1"); got != "http://all" {
+		t.Errorf("eu-west-1: got %q", got)
+	}
+	if got := (EndpointOptions{}).ec2Endpoint("eu-west-1"); got != "" {
+		t.Errorf("no overrides: got %q", got)
+	}
+}
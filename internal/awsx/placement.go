@@ -0,0 +1,185 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/devplatformsolutions/leftover/internal/metrics"
+)
+
+// ScoreBudget limits GetSpotPlacementScores calls across all regions, since the
+// quota applies to the whole account.
+type ScoreBudget struct {
+	// RequestsPerHour is the sustained request rate. Zero or negative disables the budget.
+	RequestsPerHour float64
+	// Burst is the number of requests that may be made back to back.
+	Burst int
+}
+
+// DefaultScoreBudget returns a conservative account-wide budget.
+func DefaultScoreBudget() ScoreBudget {
+	return ScoreBudget{RequestsPerHour: 60, Burst: 20}
+}
+
+// DefaultMarketScoreBudget returns the account-wide budget of SpotMarket
+// snapshots; their scores are informational.
+func DefaultMarketScoreBudget() ScoreBudget {
+	return ScoreBudget{RequestsPerHour: 20, Burst: 10}
+}
+
+func (b ScoreBudget) limiter() *rate.Limiter {
+	if b.RequestsPerHour <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	burst := b.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(b.RequestsPerHour/3600), burst)
+}
+
+// errScoreBudget reports that the budget ran out before a placement score
+// request was complete.
+var errScoreBudget = errors.New("placement score budget exhausted")
+
+// TypeScores holds placement scores per instance type and AZ ID.
+type TypeScores struct {
+	// ByType maps instance type -> AZ ID -> score (1..10).
+	ByType map[string]map[string]int32
+	// Stale lists types whose scores are the last known values because the budget was exhausted.
+	Stale []string
+	// Missing lists types without any score because the budget was exhausted and nothing was cached.
+	Missing []string
+}
+
+// lastScores remembers the most recent scores per request key so that
+// requests which cannot be refreshed still have something to fall back to.
+type lastScores struct {
+	mu sync.Mutex
+	m  map[string]map[string]int32
+}
+
+func (l *lastScores) put(key string, scores map[string]int32) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.m == nil {
+		l.m = make(map[string]map[string]int32)
+	}
+	l.m[key] = scores
+}
+
+func (l *lastScores) get(key string) (map[string]int32, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.m[key]
+	return s, ok
+}
+
+// TypePlacementScores scores each instance type with a request of its own:
+// GetSpotPlacementScores rates the whole set of types it is sent, so only a
+// single-type request yields that type's score. instanceTypes should be
+// ordered by priority (most likely to win on price first): when the
+// account-wide budget runs out it is the least promising types that fall back
+// to their last known scores.
+func (c *Client) TypePlacementScores(ctx context.Context, instanceTypes []string, targetCount int32) (*TypeScores, error) {
+	if targetCount <= 0 {
+		targetCount = 1
+	}
+	region := c.EC2.Options().Region
+	out := &TypeScores{ByType: make(map[string]map[string]int32, len(instanceTypes))}
+
+	for _, it := range instanceTypes {
+		key := strconv.Itoa(int(targetCount)) + "/" + it
+		err := c.scoreRequest(ctx, out, region, key, []string{it}, func(ctx context.Context) (map[string]int32, error) {
+			return c.placementScores(ctx, []string{it}, targetCount)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// scoreRequest serves the scores of key from the cache or, within the budget,
+// from fetch, and assigns them to instanceTypes in out. When the budget is
+// exhausted, instanceTypes get the last known scores of key instead.
+func (c *Client) scoreRequest(ctx context.Context, out *TypeScores, region, key string, instanceTypes []string, fetch func(context.Context) (map[string]int32, error)) error {
+	_, fresh := c.cache.lookup(cacheKindScores, key)
+	var scores map[string]int32
+	err := errScoreBudget
+	if fresh || c.scoreBudget.Limit() == rate.Inf || c.scoreBudget.Tokens() >= 1 {
+		scores, err = cached(ctx, c.cache, cacheKindScores, key, c.ttls.Scores, fetch)
+	}
+	switch {
+	case errors.Is(err, errScoreBudget):
+		last, ok := c.lastScores.get(key)
+		outcome := "missing"
+		if ok {
+			outcome = "stale"
+		}
+		for _, it := range instanceTypes {
+			if ok {
+				out.ByType[it] = last
+				out.Stale = append(out.Stale, it)
+			} else {
+				out.Missing = append(out.Missing, it)
+			}
+		}
+		metrics.PlacementScoreRequests.WithLabelValues(region, outcome).Inc()
+		return nil
+	case err != nil:
+		return err
+	case fresh:
+		metrics.PlacementScoreRequests.WithLabelValues(region, "fresh").Inc()
+	default:
+		metrics.PlacementScoreRequests.WithLabelValues(region, "sent").Inc()
+	}
+	c.lastScores.put(key, scores)
+	for _, it := range instanceTypes {
+		out.ByType[it] = scores
+	}
+	return nil
+}
+
+// TypesByPrice returns the distinct instance types in quotes ordered by their
+// cheapest quote, which is the order in which they are most likely to be picked.
+func TypesByPrice(quotes map[[2]string]SpotQuote) []string {
+	cheapest := make(map[string]float64)
+	for _, q := range quotes {
+		if p, ok := cheapest[q.InstanceType]; !ok || q.PriceUSD < p {
+			cheapest[q.InstanceType] = q.PriceUSD
+		}
+	}
+	out := make([]string, 0, len(cheapest))
+	for it := range cheapest {
+		out = append(out, it)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if cheapest[out[i]] != cheapest[out[j]] {
+			return cheapest[out[i]] < cheapest[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// scoreServer answers GetSpotPlacementScores with a per-type score in two
+// AZs. Types listed in paged return each AZ on its own page.
+type scoreServer struct {
+	scores map[string]int32
+	paged  map[string]bool
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *scoreServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	it := r.Form.Get("InstanceType.1")
+	s.mu.Lock()
+	s.calls = append(s.calls, it)
+	s.mu.Unlock()
+
+	item := func(az string) string {
+		return fmt.Sprintf("<item><availabilityZoneId>%s</availabilityZoneId><region>us-east-1</region><score>%d</score></item>", az, s.scores[it])
+	}
+	body, next := item("use1-az1")+item("use1-az2"), ""
+	if s.paged[it] {
+		if r.Form.Get("NextToken") == "" {
+			body, next = item("use1-az1"), "<nextToken>page2</nextToken>"
+		} else {
+			body = item("use1-az2")
+		}
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<GetSpotPlacementScoresResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>r</requestId><spotPlacementScoreSet>%s</spotPlacementScoreSet>%s</GetSpotPlacementScoresResponse>`, body, next)
+}
+
+func testClient(t *testing.T, h http.Handler, budget ScoreBudget) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return &Client{
+		EC2: ec2.New(ec2.Options{
+			Region:       "us-east-1",
+			BaseEndpoint: aws.String(srv.URL),
+			Credentials:  aws.AnonymousCredentials{},
+		}),
+		ttls:        DefaultCacheTTLs(),
+		cache:       newTTLCache(),
+		scoreBudget: budget.limiter(),
+		lastScores:  &lastScores{},
+	}
+}
+
+func TestTypePlacementScores(t *testing.T) {
+	srv := &scoreServer{
+		scores: map[string]int32{"g5.xlarge": 9, "g6.xlarge": 3, "p5.48xlarge": 5},
+		paged:  map[string]bool{"p5.48xlarge": true},
+	}
+	// Four calls with no refill: one each for g5 and g6, then p5 needs two.
+	c := testClient(t, srv, ScoreBudget{RequestsPerHour: 1e-9, Burst: 4})
+	types := []string{"g5.xlarge", "g6.xlarge", "p5.48xlarge"}
+
+	got, err := c.TypePlacementScores(context.Background(), types, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]int32{
+		"g5.xlarge":   {"use1-az1": 9, "use1-az2": 9},
+		"g6.xlarge":   {"use1-az1": 3, "use1-az2": 3},
+		"p5.48xlarge": {"use1-az1": 5, "use1-az2": 5},
+	}
+	if !reflect.DeepEqual(got.ByType, want) || len(got.Stale)+len(got.Missing) != 0 {
+		t.Fatalf("scores = %v stale=%v missing=%v, want %v", got.ByType, got.Stale, got.Missing, want)
+	}
+	if want := []string{"g5.xlarge", "g6.xlarge", "p5.48xlarge", "p5.48xlarge"}; !reflect.DeepEqual(srv.calls, want) {
+		t.Fatalf("calls = %v, want one request per type and page %v", srv.calls, want)
+	}
+
+	// Cached scores are served without calls or tokens.
+	if _, err := c.TypePlacementScores(context.Background(), types[:2], 2); err != nil || len(srv.calls) != 4 {
+		t.Fatalf("cached lookup: err=%v calls=%v", err, srv.calls)
+	}
+
+	// Once they expire, the empty budget falls back to the last known scores
+	// of each type; a type never scored is missing.
+	c.cache = newTTLCache()
+	got, err = c.TypePlacementScores(context.Background(), []string{"g6.xlarge", "g4dn.xlarge"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.calls) != 4 {
+		t.Fatalf("calls = %v, want no calls without budget", srv.calls)
+	}
+	if !reflect.DeepEqual(got.ByType, map[string]map[string]int32{"g6.xlarge": want["g6.xlarge"]}) ||
+		!reflect.DeepEqual(got.Stale, []string{"g6.xlarge"}) || !reflect.DeepEqual(got.Missing, []string{"g4dn.xlarge"}) {
+		t.Fatalf("fallback = %v stale=%v missing=%v", got.ByType, got.Stale, got.Missing)
+	}
+
+	// Last scores are kept per target capacity.
+	got, err = c.TypePlacementScores(context.Background(), []string{"g6.xlarge"}, 10)
+	if err != nil || !reflect.DeepEqual(got.Missing, []string{"g6.xlarge"}) {
+		t.Fatalf("other target: err=%v missing=%v", err, got.Missing)
+	}
+}
+
+func TestTypePlacementScoresBudgetRunsOutMidRequest(t *testing.T) {
+	srv := &scoreServer{scores: map[string]int32{"p5.48xlarge": 5}, paged: map[string]bool{"p5.48xlarge": true}}
+	c := testClient(t, srv, ScoreBudget{RequestsPerHour: 1e-9, Burst: 1})
+
+	got, err := c.TypePlacementScores(context.Background(), []string{"p5.48xlarge"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.calls) != 1 || !reflect.DeepEqual(got.Missing, []string{"p5.48xlarge"}) {
+		t.Fatalf("calls=%v missing=%v, want the second page refused", srv.calls, got.Missing)
+	}
+}
+
+func TestTypePlacementScoresUnlimited(t *testing.T) {
+	srv := &scoreServer{scores: map[string]int32{"g5.xlarge": 9}}
+	c := testClient(t, srv, ScoreBudget{})
+
+	got, err := c.TypePlacementScores(context.Background(), []string{"g5.xlarge"}, 1)
+	if err != nil || got.ByType["g5.xlarge"]["use1-az1"] != 9 {
+		t.Fatalf("err=%v scores=%v", err, got)
+	}
+}
+
+func TestTypesByPrice(t *testing.T) {
+	quotes := map[[2]string]SpotQuote{
+		{"g6.xlarge", "a"}:   {InstanceType: "g6.xlarge", PriceUSD: 0.5},
+		{"g6.xlarge", "b"}:   {InstanceType: "g6.xlarge", PriceUSD: 0.2},
+		{"g5.xlarge", "a"}:   {InstanceType: "g5.xlarge", PriceUSD: 0.3},
+		{"g4dn.xlarge", "a"}: {InstanceType: "g4dn.xlarge", PriceUSD: 0.3},
+	}
+	want := []string{"g6.xlarge", "g4dn.xlarge", "g5.xlarge"}
+	if got := TypesByPrice(quotes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("TypesByPrice = %v, want %v", got, want)
+	}
+}
+
+func TestForMarketUsesItsOwnBudget(t *testing.T) {
+	srv := &scoreServer{scores: map[string]int32{"g5.xlarge": 9, "g6.xlarge": 3}}
+	c := testClient(t, srv, ScoreBudget{RequestsPerHour: 1e-9, Burst: 1})
+	c.marketScoreBudget = ScoreBudget{RequestsPerHour: 1e-9, Burst: 1}.limiter()
+	market := c.ForMarket()
+
+	got, err := market.TypePlacementScores(context.Background(), []string{"g5.xlarge", "g6.xlarge"}, 1)
+	if err != nil || !reflect.DeepEqual(got.Missing, []string{"g6.xlarge"}) {
+		t.Fatalf("market: err=%v missing=%v, want its budget spent on g5.xlarge", err, got.Missing)
+	}
+	// The pool budget is untouched, and the market's scores are shared.
+	got, err = c.TypePlacementScores(context.Background(), []string{"g5.xlarge", "g6.xlarge"}, 1)
+	if err != nil || len(got.Missing)+len(got.Stale) != 0 || len(srv.calls) != 2 {
+		t.Fatalf("pool: err=%v missing=%v stale=%v calls=%v", err, got.Missing, got.Stale, srv.calls)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingtypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+// priceListItem is the subset of a Price List API product document we read.
+type priceListItem struct {
+	Product struct {
+		Attributes struct {
+			InstanceType string `json:"instanceType"`
+		} `json:"attributes"`
+	} `json:"product"`
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				PricePerUnit map[string]string `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+// OnDemandPrices returns the on-demand price (USD/hour) of product per instance
+// type in the client's region. It returns an empty map where the partition has
+// no Price List API. The returned map is cached and must not be mutated.
+func (c *Client) OnDemandPrices(ctx context.Context, product string) (map[string]float64, error) {
+	if c.Pricing == nil {
+		return map[string]float64{}, nil
+	}
+	os := pricingOperatingSystem(normalizeProduct(product))
+	return cached(ctx, c.cache, cacheKindOnDemand, os, c.ttls.OnDemandPrices, func(ctx context.Context) (map[string]float64, error) {
+		return c.getOnDemandPrices(ctx, os)
+	})
+}
+
+func (c *Client) getOnDemandPrices(ctx context.Context, operatingSystem string) (map[string]float64, error) {
+	term := func(field, value string) pricingtypes.Filter {
+		return pricingtypes.Filter{Type: pricingtypes.FilterTypeTermMatch, Field: aws.String(field), Value: aws.String(value)}
+	}
+	in := &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters: []pricingtypes.Filter{
+			term("regionCode", c.EC2.Options().Region),
+			term("operatingSystem", operatingSystem),
+			term("tenancy", "Shared"),
+			term("preInstalledSw", "NA"),
+			term("capacitystatus", "Used"),
+			term("licenseModel", "No License required"),
+		},
+	}
+	p := pricing.NewGetProductsPaginator(c.Pricing, in)
+	out := make(map[string]float64)
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range page.PriceList {
+			var item priceListItem
+			if err := json.Unmarshal([]byte(doc), &item); err != nil {
+				continue
+			}
+			it := item.Product.Attributes.InstanceType
+			if it == "" {
+				continue
+			}
+			for _, t := range item.Terms.OnDemand {
+				for _, d := range t.PriceDimensions {
+					usd, ok := d.PricePerUnit["USD"]
+					if !ok {
+						continue
+					}
+					if price, err := strconv.ParseFloat(usd, 64); err == nil && price > 0 {
+						out[it] = price
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// Spot price product descriptions, without the "(Amazon VPC)" suffix that the
+// commercial partition adds (see Partition.ProductDescription).
+const (
+	ProductLinux   = "Linux/UNIX"
+	ProductSUSE    = "SUSE Linux"
+	ProductRHEL    = "Red Hat Enterprise Linux"
+	ProductWindows = "Windows"
+)
+
+// ProductForAMIFamily maps a Karpenter amiFamily or amiSelectorTerms alias
+// (e.g. "AL2023", "windows2022@latest") to a product description. ok is false
+// for families that do not imply an OS, such as Custom.
+func ProductForAMIFamily(family string) (product string, ok bool) {
+	f := strings.ToLower(family)
+	if i := strings.IndexByte(f, '@'); i >= 0 {
+		f = f[:i]
+	}
+	switch {
+	case strings.HasPrefix(f, "windows"):
+		return ProductWindows, true
+	case f == "al2", f == "al2023", f == "bottlerocket", f == "ubuntu":
+		return ProductLinux, true
+	}
+	return "", false
+}
+
+// ProductForPlatform maps an AMI's PlatformDetails to a product description.
+// BYOL and unrecognized platforms are billed like Linux/UNIX.
+func ProductForPlatform(platformDetails string) string {
+	switch {
+	case strings.HasPrefix(platformDetails, "Windows"):
+		return ProductWindows
+	case strings.HasPrefix(platformDetails, "Red Hat Enterprise Linux"):
+		return ProductRHEL
+	case strings.HasPrefix(platformDetails, "SUSE Linux"):
+		return ProductSUSE
+	}
+	return ProductLinux
+}
+
+// pricingOperatingSystem maps a product description to the Price List API operatingSystem attribute.
+func pricingOperatingSystem(product string) string {
+	switch product {
+	case ProductWindows:
+		return "Windows"
+	case ProductRHEL:
+		return "RHEL"
+	case ProductSUSE:
+		return "SUSE"
+	}
+	return "Linux"
+}
+
+// ProductSlug returns a DNS-label-safe suffix for product, or "" for Linux/UNIX
+// so that Linux keeps the unsuffixed names.
+func ProductSlug(product string) string {
+	product = normalizeProduct(product)
+	if product == ProductLinux {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(product))
+}
+
+func normalizeProduct(product string) string {
+	if product == "" {
+		return ProductLinux
+	}
+	return product
+}
+
+// ImageProduct returns the product description billed for the given AMIs. When
+// they differ, the most expensive family wins (Windows, then RHEL, then SUSE).
+// Results are cached with the instance type TTL since AMIs are immutable.
+func (c *Client) ImageProduct(ctx context.Context, amiIDs []string) (string, error) {
+	ids := append([]string(nil), amiIDs...)
+	sort.Strings(ids)
+	return cached(ctx, c.cache, cacheKindImages, strings.Join(ids, ","), c.ttls.InstanceTypes, func(ctx context.Context) (string, error) {
+		out, err := c.EC2.DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: ids})
+		if err != nil {
+			return "", err
+		}
+		rank := map[string]int{ProductLinux: 0, ProductSUSE: 1, ProductRHEL: 2, ProductWindows: 3}
+		product := ProductLinux
+		for _, img := range out.Images {
+			if img.PlatformDetails == nil {
+				continue
+			}
+			if p := ProductForPlatform(*img.PlatformDetails); rank[p] > rank[product] {
+				product = p
+			}
+		}
+		return product, nil
+	})
+}
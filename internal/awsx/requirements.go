@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsx
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Range is an inclusive range; nil bounds are open.
+type Range struct {
+	Min *int32 `json:"min,omitempty"`
+	Max *int32 `json:"max,omitempty"`
+}
+
+// InstanceRequirements selects instance types by attributes, the way EC2
+// Fleet interprets InstanceRequirements. Accelerator types default to gpu.
+type InstanceRequirements struct {
+	AcceleratorTypes          []string `json:"acceleratorTypes,omitempty"`
+	AcceleratorManufacturers  []string `json:"acceleratorManufacturers,omitempty"`
+	AcceleratorNames          []string `json:"acceleratorNames,omitempty"`
+	AcceleratorCount          Range    `json:"acceleratorCount"`
+	AcceleratorTotalMemoryMiB Range    `json:"acceleratorTotalMemoryMiB"`
+	VCPUCount                 Range    `json:"vCpuCount"`
+	MemoryMiB                 Range    `json:"memoryMiB"`
+	// ExcludedInstanceTypes may use * wildcards, e.g. "g4ad.*".
+	ExcludedInstanceTypes []string `json:"excludedInstanceTypes,omitempty"`
+}
+
+// Instance types from requirements are limited to HVM on either architecture.
+var (
+	requirementArchitectures   = []types.ArchitectureType{types.ArchitectureTypeX8664, types.ArchitectureTypeArm64}
+	requirementVirtualizations = []types.VirtualizationType{types.VirtualizationTypeHvm}
+)
+
+// key identifies r in cache keys.
+func (r InstanceRequirements) key() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// request converts r; EC2 requires the vCPU and memory minimums, which
+// default to zero.
+func (r InstanceRequirements) request() *types.InstanceRequirementsRequest {
+	accelerators := r.AcceleratorTypes
+	if len(accelerators) == 0 {
+		accelerators = []string{string(types.AcceleratorTypeGpu)}
+	}
+	req := &types.InstanceRequirementsRequest{
+		VCpuCount:             &types.VCpuCountRangeRequest{Min: orZero(r.VCPUCount.Min), Max: r.VCPUCount.Max},
+		MemoryMiB:             &types.MemoryMiBRequest{Min: orZero(r.MemoryMiB.Min), Max: r.MemoryMiB.Max},
+		ExcludedInstanceTypes: r.ExcludedInstanceTypes,
+	}
+	for _, a := range accelerators {
+		req.AcceleratorTypes = append(req.AcceleratorTypes, types.AcceleratorType(a))
+	}
+	for _, m := range r.AcceleratorManufacturers {
+		req.AcceleratorManufacturers = append(req.AcceleratorManufacturers, types.AcceleratorManufacturer(m))
+	}
+	for _, n := range r.AcceleratorNames {
+		req.AcceleratorNames = append(req.AcceleratorNames, types.AcceleratorName(n))
+	}
+	if r.AcceleratorCount.Min != nil || r.AcceleratorCount.Max != nil {
+		req.AcceleratorCount = &types.AcceleratorCountRequest{Min: r.AcceleratorCount.Min, Max: r.AcceleratorCount.Max}
+	}
+	if r.AcceleratorTotalMemoryMiB.Min != nil || r.AcceleratorTotalMemoryMiB.Max != nil {
+		req.AcceleratorTotalMemoryMiB = &types.AcceleratorTotalMemoryMiBRequest{Min: r.AcceleratorTotalMemoryMiB.Min, Max: r.AcceleratorTotalMemoryMiB.Max}
+	}
+	return req
+}
+
+func orZero(v *int32) *int32 {
+	if v == nil {
+		return aws.Int32(0)
+	}
+	return v
+}
+
+// ListInstanceTypesByRequirements returns the GPU instance types matching req
+// (filtered by families and min GPUs like ListGPUInstanceTypes) and their meta.
+// Only the matching types are described.
+func (c *Client) ListInstanceTypesByRequirements(ctx context.Context, req InstanceRequirements, families []string, minGPUs int) ([]string, map[string]InstanceMeta, error) {
+	all, err := cached(ctx, c.cache, cacheKindInstanceTypes, "requirements/"+req.key(), c.ttls.InstanceTypes, func(ctx context.Context) ([]InstanceMeta, error) {
+		return c.describeInstanceTypesByRequirements(ctx, req)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	instances, meta := filterGPUTypes(all, families, minGPUs)
+	return instances, meta, nil
+}
+
+// describeInstanceTypesMaxNames is the DescribeInstanceTypes limit on instance type names per request.
+const describeInstanceTypesMaxNames = 100
+
+func (c *Client) describeInstanceTypesByRequirements(ctx context.Context, req InstanceRequirements) ([]InstanceMeta, error) {
+	p := ec2.NewGetInstanceTypesFromInstanceRequirementsPaginator(c.EC2, &ec2.GetInstanceTypesFromInstanceRequirementsInput{
+		ArchitectureTypes:    requirementArchitectures,
+		VirtualizationTypes:  requirementVirtualizations,
+		InstanceRequirements: req.request(),
+	})
+	var names []types.InstanceType
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range page.InstanceTypes {
+			if name := aws.ToString(it.InstanceType); name != "" {
+				names = append(names, types.InstanceType(name))
+			}
+		}
+	}
+
+	var out []InstanceMeta
+	for start := 0; start < len(names); start += describeInstanceTypesMaxNames {
+		chunk := names[start:min(start+describeInstanceTypesMaxNames, len(names))]
+		dp := ec2.NewDescribeInstanceTypesPaginator(c.EC2, &ec2.DescribeInstanceTypesInput{InstanceTypes: chunk})
+		for dp.HasMorePages() {
+			page, err := dp.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, it := range page.InstanceTypes {
+				if m, ok := gpuInstanceMeta(it); ok {
+					out = append(out, m)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// RequirementsPlacementScores scores req with one InstanceRequirementsWithMetadata
+// request; the per-AZ scores of the whole set apply to each of instanceTypes.
+// Like TypePlacementScores it uses the cache and the account-wide budget, and
+// falls back to the last known scores when the budget is exhausted.
+func (c *Client) RequirementsPlacementScores(ctx context.Context, req InstanceRequirements, instanceTypes []string, targetCount int32) (*TypeScores, error) {
+	if targetCount <= 0 {
+		targetCount = 1
+	}
+	region := c.EC2.Options().Region
+	out := &TypeScores{ByType: make(map[string]map[string]int32, len(instanceTypes))}
+	key := strconv.Itoa(int(targetCount)) + "/requirements/" + req.key()
+
+	err := c.scoreRequest(ctx, out, region, key, instanceTypes, func(ctx context.Context) (map[string]int32, error) {
+		in := c.placementScoresInput(targetCount)
+		in.InstanceRequirementsWithMetadata = &types.InstanceRequirementsWithMetadataRequest{
+			ArchitectureTypes:    requirementArchitectures,
+			VirtualizationTypes:  requirementVirtualizations,
+			InstanceRequirements: req.request(),
+		}
+		return c.spotPlacementScores(ctx, in)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -111,9 +111,11 @@ import (
 type QuoteScorer struct {
 	cli        *Client
 	azNameToID map[string]string
-	azScores   map[string]int32
+	scores     *TypeScores
 }
 
+// NewQuoteScorer fetches placement scores for instanceTypes, which should be
+// ordered by priority (see TypesByPrice).
 func NewQuoteScorer(ctx context.Context, cli *Client, instanceTypes []string, targetCount int32) (*QuoteScorer, error) {
 	azMap, err := cli.AZNameToID(ctx)
 	if err != nil {
@@ -122,14 +124,32 @@ func NewQuoteScorer(ctx context.Context, cli *Client, instanceTypes []string, ta
 	if targetCount <= 0 {
 		targetCount = 1
 	}
-	scores, err := cli.PlacementScores(ctx, instanceTypes, targetCount)
+	scores, err := cli.TypePlacementScores(ctx, instanceTypes, targetCount)
 	if err != nil {
 		return nil, err
 	}
 	return &QuoteScorer{
 		cli:        cli,
 		azNameToID: azMap,
-		azScores:   scores,
+		scores:     scores,
+	}, nil
+}
+
+// NewRequirementsScorer is NewQuoteScorer with the placement scores of req
+// (see RequirementsPlacementScores) applied to every instance type.
+func NewRequirementsScorer(ctx context.Context, cli *Client, req InstanceRequirements, instanceTypes []string, targetCount int32) (*QuoteScorer, error) {
+	azMap, err := cli.AZNameToID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := cli.RequirementsPlacementScores(ctx, req, instanceTypes, targetCount)
+	if err != nil {
+		return nil, err
+	}
+	return &QuoteScorer{
+		cli:        cli,
+		azNameToID: azMap,
+		scores:     scores,
 	}, nil
 }
 
@@ -138,45 +158,82 @@ func (s *QuoteScorer) ScoreFor(ctx context.Context, instanceType, azName string)
 	if azID == "" {
 		return 0, nil
 	}
-	return s.azScores[azID], nil
+	return s.scores.ByType[instanceType][azID], nil
+}
+
+// Degraded reports how many instance types were scored from stale data or not
+// scored at all because the placement score budget was exhausted.
+func (s *QuoteScorer) Degraded() (stale, missing int) {
+	return len(s.scores.Stale), len(s.scores.Missing)
 }
 
 func (s *QuoteScorer) PickCheapestInBatches(ctx context.Context, quotes map[[2]string]SpotQuote, window int, threshold int32) (*SpotQuote, int32, bool, error) {
+	return s.PickBestInBatches(ctx, quotes, window, threshold, nil, nil)
+}
+
+// PickBestInBatches is PickCheapestInBatches with quotes ranked by cost instead
+// of their current price, skipping quotes that accept rejects. A nil cost ranks
+// by price; a nil accept accepts everything. It returns nil when every quote
+// was rejected.
+func (s *QuoteScorer) PickBestInBatches(ctx context.Context, quotes map[[2]string]SpotQuote, window int, threshold int32, cost func(SpotQuote) float64, accept func(SpotQuote) bool) (*SpotQuote, int32, bool, error) {
+	top, err := s.PickTopInBatches(ctx, quotes, window, 1, threshold, cost, accept)
+	if err != nil || len(top) == 0 {
+		return nil, 0, false, err
+	}
+	return &top[0].SpotQuote, top[0].Score, top[0].MeetsThreshold, nil
+}
+
+// ScoredQuote is a quote with its placement score.
+type ScoredQuote struct {
+	SpotQuote
+	Score int32
+	// MeetsThreshold reports whether Score reached the requested threshold.
+	MeetsThreshold bool
+}
+
+// PickTopInBatches returns up to n accepted quotes ranked like
+// PickBestInBatches: those meeting threshold in cost order, then, if fewer
+// than n do, the cheapest of the others.
+func (s *QuoteScorer) PickTopInBatches(ctx context.Context, quotes map[[2]string]SpotQuote, window, n int, threshold int32, cost func(SpotQuote) float64, accept func(SpotQuote) bool) ([]ScoredQuote, error) {
 	if window <= 0 {
 		window = 5
 	}
+	if cost == nil {
+		cost = func(q SpotQuote) float64 { return q.PriceUSD }
+	}
 
 	list := make([]SpotQuote, 0, len(quotes))
-	for _, q := range quotes {
+	costs := make(map[[2]string]float64, len(quotes))
+	for k, q := range quotes {
 		list = append(list, q)
+		costs[k] = cost(q)
 	}
-	sort.Slice(list, func(i, j int) bool { return list[i].PriceUSD < list[j].PriceUSD })
-
-	if len(list) == 0 {
-		return nil, 0, false, nil
-	}
-
-	cheapest := list[0]
-	cheapestScore, err := s.ScoreFor(ctx, cheapest.InstanceType, cheapest.Zone)
-	if err != nil {
-		return nil, 0, false, err
-	}
-
-	for start := 0; start < len(list); start += window {
-		end := start + window
-		if end > len(list) {
-			end = len(list)
-		}
-		for i := start; i < end; i++ {
+	sort.Slice(list, func(i, j int) bool {
+		return costs[[2]string{list[i].InstanceType, list[i].Zone}] < costs[[2]string{list[j].InstanceType, list[j].Zone}]
+	})
+
+	var top, below []ScoredQuote
+	for start := 0; start < len(list) && len(top) < n; start += window {
+		end := min(start+window, len(list))
+		for i := start; i < end && len(top) < n; i++ {
 			q := list[i]
 			score, err := s.ScoreFor(ctx, q.InstanceType, q.Zone)
 			if err != nil {
-				return nil, 0, false, err
+				return nil, err
 			}
-			if score >= threshold {
-				return &q, score, true, nil
+			if accept != nil && !accept(q) {
+				continue
+			}
+			sq := ScoredQuote{SpotQuote: q, Score: score, MeetsThreshold: score >= threshold}
+			if sq.MeetsThreshold {
+				top = append(top, sq)
+			} else if len(below) < n {
+				below = append(below, sq)
 			}
 		}
 	}
-	return &cheapest, cheapestScore, false, nil
+	if len(top) < n {
+		top = append(top, below[:min(n-len(top), len(below))]...)
+	}
+	return top, nil
 }
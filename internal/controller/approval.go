@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+)
+
+// defaultApprovalTimeout bounds how long a pending selection can be approved.
+const defaultApprovalTimeout = 24 * time.Hour
+
+// approvalTimeout returns spec.approvalTimeout, or the default if unset or
+// invalid.
+func approvalTimeout(cr *gpuv1alpha1.LeftoverNodePool) time.Duration {
+	if cr.Spec.ApprovalTimeout != "" {
+		if d, err := time.ParseDuration(cr.Spec.ApprovalTimeout); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultApprovalTimeout
+}
+
+// approved reports whether the approve-selection annotation carries the hash
+// of the change from current to choices. Otherwise the change is recorded in
+// status.pendingSelection awaiting approval, and each new request is
+// announced with an Event. A hash of another request is rejected as stale
+// and removed; a matching one is removed by the caller once the selection
+// has been applied, so an approval applies to one request only and survives
+// a failed apply.
+func (r *LeftoverNodePoolReconciler) approved(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, current, choices []choice) bool {
+	prevHash := ""
+	if prev := cr.Status.PendingSelection; prev != nil {
+		prevHash = prev.Hash
+	}
+	holdSelection(cr, current, choices, gpuv1alpha1.PendingAwaitingApproval, time.Time{})
+	p := cr.Status.PendingSelection
+	now := time.Now()
+	timeout := approvalTimeout(cr)
+	if !now.Before(p.Since.Add(timeout)) {
+		// Expired: request approval again under a new hash.
+		p.Since = metav1.NewTime(now.Truncate(time.Second))
+	}
+	p.ExpiresAt = &metav1.Time{Time: p.Since.Add(timeout)}
+	p.Hash = selectionHash(p)
+
+	if p.Hash != prevHash {
+		log.Info("Selection change awaiting approval", "instanceType", choices[0].quote.InstanceType,
+			"zone", choices[0].quote.Zone, "hash", p.Hash, "expiresAt", p.ExpiresAt)
+		r.eventf(cr, corev1.EventTypeNormal, "SelectionApprovalRequired",
+			"%s ($%s, score %d) would replace %s ($%s, score %d); approve with annotation %s=%s before %s",
+			describeOfferings(choices), p.PriceUSD, p.Score, describeOfferings(current), p.CurrentPriceUSD, p.CurrentScore,
+			gpuv1alpha1.AnnotationApproveSelection, p.Hash, p.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	approval, ok := cr.Annotations[gpuv1alpha1.AnnotationApproveSelection]
+	if !ok {
+		return false
+	}
+	if approval != p.Hash {
+		r.clearApproval(ctx, log, cr)
+		log.Info("Rejecting stale selection approval", "approval", approval, "hash", p.Hash)
+		r.eventf(cr, corev1.EventTypeWarning, "StaleApproval",
+			"Approval %q does not match the pending selection %s; annotation removed", approval, p.Hash)
+		return false
+	}
+	log.Info("Selection change approved", "hash", p.Hash)
+	r.eventf(cr, corev1.EventTypeNormal, "SelectionApproved", "Applying approved selection %s (%s)", describeOfferings(choices), p.Hash)
+	return true
+}
+
+// finishApproval removes the approval of an applied selection together with
+// its pending request.
+func (r *LeftoverNodePoolReconciler) finishApproval(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool) {
+	r.clearApproval(ctx, log, cr)
+	cr.Status.PendingSelection = nil
+}
+
+// selectionHash identifies a pending selection: its offerings and the time
+// approval was requested.
+func selectionHash(p *gpuv1alpha1.PendingSelection) string {
+	h := sha256.New()
+	for i := range p.InstanceTypes {
+		fmt.Fprintf(h, "%s/%s\n", p.InstanceTypes[i], p.Zones[i])
+	}
+	h.Write([]byte(p.Since.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// clearApproval removes the approve-selection annotation. cr takes the new
+// resourceVersion so its status can still be updated; its spec and status
+// are left as they are.
+func (r *LeftoverNodePoolReconciler) clearApproval(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool) {
+	obj := &gpuv1alpha1.LeftoverNodePool{ObjectMeta: metav1.ObjectMeta{Name: cr.Name}}
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, gpuv1alpha1.AnnotationApproveSelection)
+	if err := r.Patch(ctx, obj, client.RawPatch(types.MergePatchType, []byte(patch))); err != nil {
+		log.Error(err, "Removing the approval annotation failed")
+		return
+	}
+	cr.ResourceVersion = obj.ResourceVersion
+	delete(cr.Annotations, gpuv1alpha1.AnnotationApproveSelection)
+}
+
+// describeOfferings renders choices as "type/zone, ...".
+func describeOfferings(choices []choice) string {
+	out := make([]string, 0, len(choices))
+	for _, c := range choices {
+		out = append(out, c.quote.InstanceType+"/"+c.quote.Zone)
+	}
+	return strings.Join(out, ", ")
+}
+
+// eventf emits an event on cr if a recorder is configured.
+func (r *LeftoverNodePoolReconciler) eventf(cr *gpuv1alpha1.LeftoverNodePool, eventType, reason, format string, args ...any) {
+	if r.Recorder != nil {
+		r.Recorder.Eventf(cr, eventType, reason, format, args...)
+	}
+}
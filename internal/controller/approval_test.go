@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+)
+
+func TestSelectionHash(t *testing.T) {
+	since := metav1.NewTime(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	p := &gpuv1alpha1.PendingSelection{InstanceTypes: []string{"g6.xlarge"}, Zones: []string{"us-east-1b"}, Since: since}
+	h := selectionHash(p)
+	if len(h) != 16 || selectionHash(p) != h {
+		t.Fatalf("hash %q is not stable", h)
+	}
+	other := p.DeepCopy()
+	other.Zones = []string{"us-east-1c"}
+	later := p.DeepCopy()
+	later.Since = metav1.NewTime(since.Add(time.Second))
+	if selectionHash(other) == h || selectionHash(later) == h {
+		t.Fatal("hash ignores the offerings or the request time")
+	}
+}
+
+func TestApprovalFlow(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := gpuv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	stored := &gpuv1alpha1.LeftoverNodePool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stored).Build()
+	r := &LeftoverNodePoolReconciler{Client: c}
+	ctx, log := t.Context(), logr.Discard()
+
+	cr := &gpuv1alpha1.LeftoverNodePool{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "pool"}, cr); err != nil {
+		t.Fatal(err)
+	}
+	current := []choice{testChoice("g5.xlarge", "us-east-1a", 1.0, 3)}
+	choices := []choice{testChoice("g6.xlarge", "us-east-1b", 0.8, 9)}
+	annotate := func(v string) {
+		t.Helper()
+		cr.Annotations = map[string]string{gpuv1alpha1.AnnotationApproveSelection: v}
+		if err := c.Update(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	annotated := func() bool {
+		t.Helper()
+		var got gpuv1alpha1.LeftoverNodePool
+		if err := c.Get(ctx, client.ObjectKey{Name: "pool"}, &got); err != nil {
+			t.Fatal(err)
+		}
+		_, ok := got.Annotations[gpuv1alpha1.AnnotationApproveSelection]
+		return ok
+	}
+
+	// The change is requested under a hash and held.
+	if r.approved(ctx, log, cr, current, choices) {
+		t.Fatal("approved without annotation")
+	}
+	p := cr.Status.PendingSelection
+	if p == nil || p.Reason != gpuv1alpha1.PendingAwaitingApproval || p.Hash == "" || p.ExpiresAt == nil {
+		t.Fatalf("pending selection = %+v", p)
+	}
+	hash := p.Hash
+
+	// A stale hash is rejected and removed right away.
+	annotate("0123456789abcdef")
+	if r.approved(ctx, log, cr, current, choices) || annotated() {
+		t.Fatal("stale approval accepted or kept")
+	}
+	if cr.Status.PendingSelection.Hash != hash {
+		t.Fatalf("hash changed to %s", cr.Status.PendingSelection.Hash)
+	}
+
+	// The matching hash approves; the annotation stays until the apply succeeded.
+	annotate(hash)
+	if !r.approved(ctx, log, cr, current, choices) || !annotated() {
+		t.Fatal("approval rejected or removed before the apply")
+	}
+	// A failed apply retries under the same hash.
+	if !r.approved(ctx, log, cr, current, choices) || cr.Status.PendingSelection.Hash != hash {
+		t.Fatal("approval lost on retry")
+	}
+	r.finishApproval(ctx, log, cr)
+	if annotated() || cr.Status.PendingSelection != nil {
+		t.Fatal("approval not cleared after the apply")
+	}
+
+	// An expired request is renewed under a new hash.
+	r.approved(ctx, log, cr, current, choices)
+	p = cr.Status.PendingSelection
+	p.Since = metav1.NewTime(p.Since.Add(-2 * defaultApprovalTimeout))
+	p.Hash = selectionHash(p)
+	expiredHash := p.Hash
+	annotate(expiredHash)
+	if r.approved(ctx, log, cr, current, choices) || cr.Status.PendingSelection.Hash == expiredHash {
+		t.Fatal("expired approval accepted")
+	}
+}
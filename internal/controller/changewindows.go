@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/karpenterx"
+	"github.com/devplatformsolutions/leftover/internal/schedule"
+)
+
+// gateSelection holds back a changed selection outside spec.changeWindows
+// and, with approvalPolicy Manual, until it is approved: it returns the
+// current offerings and records the new ones in status.pendingSelection. A
+// current offering scoring below spec.emergencyScoreFloor lets the change
+// through change windows. approved reports that choices carry a manual
+// approval; status.pendingSelection is then kept until the caller has applied
+// them and calls finishApproval, so a failed apply retries under the same hash.
+// The first selection, with nothing applied yet, is never held.
+func (r *LeftoverNodePoolReconciler) gateSelection(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, m *market, classes *karpenterx.NodeClasses, choices []choice) (_ []choice, approved bool) {
+	windowed := len(cr.Spec.ChangeWindows) > 0 && cr.Spec.OutputMode != gpuv1alpha1.OutputNodeOverlay
+	manual := cr.Spec.ApprovalPolicy == gpuv1alpha1.ApprovalManual && cr.Spec.OutputMode != gpuv1alpha1.OutputNodeOverlay
+	if !windowed && !manual {
+		cr.Status.PendingSelection = nil
+		return choices, false
+	}
+	current := currentChoices(ctx, cr, m, classes)
+	if current == nil || sameOfferings(current, choices) {
+		cr.Status.PendingSelection = nil
+		return choices, false
+	}
+	if windowed && holdOutsideWindows(log, cr, current, choices) {
+		return current, false
+	}
+	if manual {
+		if !r.approved(ctx, log, cr, current, choices) {
+			return current, false
+		}
+		return choices, true
+	}
+	cr.Status.PendingSelection = nil
+	return choices, false
+}
+
+// holdOutsideWindows records choices as pending and reports true when no
+// change window is open and the current offering is above the emergency
+// floor.
+func holdOutsideWindows(log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, current, choices []choice) bool {
+	windows := changeWindows(log, cr)
+	now := time.Now()
+	if schedule.AnyOpen(windows, now) {
+		log.Info("Selection change inside change window")
+		return false
+	}
+	if floor := cr.Spec.EmergencyScoreFloor; floor > 0 && current[0].score < floor {
+		log.Info("Current offering scores below the emergency floor; passing selection change outside change windows",
+			"instanceType", current[0].quote.InstanceType, "zone", current[0].quote.Zone, "score", current[0].score, "floor", floor)
+		return false
+	}
+
+	holdSelection(cr, current, choices, gpuv1alpha1.PendingOutsideChangeWindow, schedule.NextOpen(windows, now))
+	log.Info("Holding selection change until the next change window",
+		"instanceType", choices[0].quote.InstanceType, "zone", choices[0].quote.Zone, "nextWindow", cr.Status.PendingSelection.NextWindow)
+	return true
+}
+
+// changeWindows parses spec.changeWindows; invalid windows, which the
+// webhook rejects, are logged and skipped.
+func changeWindows(log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool) []*schedule.Window {
+	out := make([]*schedule.Window, 0, len(cr.Spec.ChangeWindows))
+	for _, cw := range cr.Spec.ChangeWindows {
+		w, err := schedule.NewWindow(cw.Schedule, cw.Duration, cw.TimeZone)
+		if err != nil {
+			log.Error(err, "Ignoring invalid change window", "schedule", cw.Schedule)
+			continue
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+// currentChoices rebuilds the applied selection from the status, scored and
+// priced with the current market. Offerings without a quote keep the last
+// recorded price. It returns nil if there is no applied selection or its node
+// class no longer resolves.
+func currentChoices(ctx context.Context, cr *gpuv1alpha1.LeftoverNodePool, m *market, classes *karpenterx.NodeClasses) []choice {
+	types, zones := cr.Status.SelectedInstanceTypes, cr.Status.SelectedZones
+	if len(types) == 0 || len(types) != len(zones) {
+		return nil
+	}
+	lastPrice, _ := strconv.ParseFloat(cr.Status.LastPriceUSD, 64)
+	out := make([]choice, 0, len(types))
+	for i := range types {
+		q, ok := m.quotes[[2]string{types[i], zones[i]}]
+		if !ok {
+			q = awsx.SpotQuote{InstanceType: types[i], Zone: zones[i], PriceUSD: lastPrice}
+		}
+		nodeClass, perr := classes.For(q.InstanceType)
+		if perr != nil {
+			return nil
+		}
+		score, _ := m.scorer.ScoreFor(ctx, q.InstanceType, q.Zone)
+		out = append(out, choice{quote: q, nodeClass: nodeClass, score: score})
+	}
+	return out
+}
+
+// sameOfferings reports whether a and b select the same offerings in order.
+func sameOfferings(a, b []choice) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].offering() != b[i].offering() {
+			return false
+		}
+	}
+	return true
+}
+
+// holdSelection records choices as the pending selection replacing current.
+// Since is kept while the pending selection and its reason stay the same.
+func holdSelection(cr *gpuv1alpha1.LeftoverNodePool, current, choices []choice, reason string, nextWindow time.Time) {
+	p := &gpuv1alpha1.PendingSelection{
+		PriceUSD:        fmt.Sprintf("%.4f", choices[0].quote.PriceUSD),
+		Score:           choices[0].score,
+		Reason:          reason,
+		Since:           metav1.Now(),
+		CurrentPriceUSD: fmt.Sprintf("%.4f", current[0].quote.PriceUSD),
+		CurrentScore:    current[0].score,
+	}
+	for _, c := range choices {
+		p.InstanceTypes = append(p.InstanceTypes, c.quote.InstanceType)
+		p.Zones = append(p.Zones, c.quote.Zone)
+	}
+	if !nextWindow.IsZero() {
+		p.NextWindow = &metav1.Time{Time: nextWindow}
+	}
+	if prev := cr.Status.PendingSelection; prev != nil && prev.Reason == reason &&
+		reflect.DeepEqual(prev.InstanceTypes, p.InstanceTypes) && reflect.DeepEqual(prev.Zones, p.Zones) {
+		p.Since = prev.Since
+	}
+	cr.Status.PendingSelection = p
+}
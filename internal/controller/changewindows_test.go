@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+)
+
+func testChoice(instanceType, zone string, price float64, score int32) choice {
+	return choice{quote: awsx.SpotQuote{InstanceType: instanceType, Zone: zone, PriceUSD: price}, score: score}
+}
+
+func TestHoldOutsideWindows(t *testing.T) {
+	current := []choice{testChoice("g5.xlarge", "us-east-1a", 1.0, 3)}
+	choices := []choice{testChoice("g6.xlarge", "us-east-1b", 0.8, 9)}
+	cases := []struct {
+		name       string
+		window     gpuv1alpha1.TimeWindow
+		floor      int32
+		wantHold   bool
+		wantReason string
+	}{
+		// Every minute, open for an hour: always open.
+		{"inside window", gpuv1alpha1.TimeWindow{Schedule: "* * * * *", Duration: "1h"}, 0, false, ""},
+		// 29 February only: closed on any other day.
+		{"outside window", gpuv1alpha1.TimeWindow{Schedule: "0 0 29 2 *", Duration: "1m"}, 0, true, gpuv1alpha1.PendingOutsideChangeWindow},
+		{"current above floor", gpuv1alpha1.TimeWindow{Schedule: "0 0 29 2 *", Duration: "1m"}, 3, true, gpuv1alpha1.PendingOutsideChangeWindow},
+		{"current below floor", gpuv1alpha1.TimeWindow{Schedule: "0 0 29 2 *", Duration: "1m"}, 4, false, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if now := time.Now().UTC(); now.Month() == time.February && now.Day() == 29 {
+				t.Skip("the closed window opens today")
+			}
+			cr := &gpuv1alpha1.LeftoverNodePool{Spec: gpuv1alpha1.LeftoverNodePoolSpec{
+				ChangeWindows:       []gpuv1alpha1.TimeWindow{tc.window},
+				EmergencyScoreFloor: tc.floor,
+			}}
+			if got := holdOutsideWindows(logr.Discard(), cr, current, choices); got != tc.wantHold {
+				t.Fatalf("hold = %v, want %v", got, tc.wantHold)
+			}
+			p := cr.Status.PendingSelection
+			if !tc.wantHold {
+				if p != nil {
+					t.Fatalf("pending selection recorded: %+v", p)
+				}
+				return
+			}
+			if p == nil || p.Reason != tc.wantReason || p.InstanceTypes[0] != "g6.xlarge" || p.CurrentPriceUSD != "1.0000" || p.NextWindow == nil {
+				t.Fatalf("pending selection = %+v", p)
+			}
+		})
+	}
+}
+
+func TestHoldSelectionKeepsSince(t *testing.T) {
+	current := []choice{testChoice("g5.xlarge", "us-east-1a", 1.0, 3)}
+	cr := &gpuv1alpha1.LeftoverNodePool{}
+	holdSelection(cr, current, []choice{testChoice("g6.xlarge", "us-east-1b", 0.8, 9)}, gpuv1alpha1.PendingAwaitingApproval, time.Time{})
+	since := cr.Status.PendingSelection.Since
+	since.Time = since.Add(-time.Hour)
+	cr.Status.PendingSelection.Since = since
+
+	holdSelection(cr, current, []choice{testChoice("g6.xlarge", "us-east-1b", 0.7, 8)}, gpuv1alpha1.PendingAwaitingApproval, time.Time{})
+	if got := cr.Status.PendingSelection; !got.Since.Equal(&since) || got.PriceUSD != "0.7000" {
+		t.Fatalf("same offerings: pending = %+v, want since %v", got, since)
+	}
+	holdSelection(cr, current, []choice{testChoice("g6.xlarge", "us-east-1c", 0.7, 8)}, gpuv1alpha1.PendingAwaitingApproval, time.Time{})
+	if got := cr.Status.PendingSelection; got.Since.Equal(&since) {
+		t.Fatal("changed offerings kept since")
+	}
+}
+
+func TestGateSelection(t *testing.T) {
+	choices := []choice{testChoice("g6.xlarge", "us-east-1b", 0.8, 9)}
+	closed := []gpuv1alpha1.TimeWindow{{Schedule: "0 0 29 2 *", Duration: "1m"}}
+	cases := []struct {
+		name string
+		spec gpuv1alpha1.LeftoverNodePoolSpec
+	}{
+		{"ungated", gpuv1alpha1.LeftoverNodePoolSpec{}},
+		// Nothing applied yet: the first selection is never held.
+		{"first selection outside window", gpuv1alpha1.LeftoverNodePoolSpec{ChangeWindows: closed}},
+		{"first selection with manual approval", gpuv1alpha1.LeftoverNodePoolSpec{ApprovalPolicy: gpuv1alpha1.ApprovalManual}},
+		{"overlays are not gated", gpuv1alpha1.LeftoverNodePoolSpec{
+			ChangeWindows: closed, ApprovalPolicy: gpuv1alpha1.ApprovalManual, OutputMode: gpuv1alpha1.OutputNodeOverlay,
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := &gpuv1alpha1.LeftoverNodePool{Spec: tc.spec}
+			cr.Status.PendingSelection = &gpuv1alpha1.PendingSelection{Reason: gpuv1alpha1.PendingOutsideChangeWindow}
+			got, approved := (&LeftoverNodePoolReconciler{}).gateSelection(t.Context(), logr.Discard(), cr, &market{}, nil, choices)
+			if !sameOfferings(got, choices) || approved {
+				t.Fatalf("got %v (approved %v), want the new selection", describeOfferings(got), approved)
+			}
+			if cr.Status.PendingSelection != nil {
+				t.Fatalf("pending selection kept: %+v", cr.Status.PendingSelection)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/demand"
+)
+
+// demandSource returns the source configured by spec.demand, or nil.
+func (r *LeftoverNodePoolReconciler) demandSource(cr *gpuv1alpha1.LeftoverNodePool) demand.Source {
+	d := cr.Spec.Demand
+	if d == nil {
+		return nil
+	}
+	switch d.Type {
+	case gpuv1alpha1.DemandPrometheus:
+		return &demand.Prometheus{Client: r.Prometheus, Query: d.Query}
+	default:
+		perNode := d.GPUsPerNode
+		if perNode == 0 {
+			perNode = int32(cr.Spec.MinGPUs)
+		}
+		return &demand.PendingPods{
+			Reader:      r.Client,
+			Labels:      cr.Spec.Labels,
+			Taints:      cr.Spec.Taints,
+			GPUsPerNode: perNode,
+		}
+	}
+}
+
+// targetCount returns the placement score target capacity: spec.targetCount,
+// or the spec.demand estimate with spec.targetCount as the floor. A failing
+// estimate falls back to spec.targetCount and is reported in status.demand.
+func (r *LeftoverNodePoolReconciler) targetCount(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool) int32 {
+	floor := max(cr.Spec.TargetCount, 1)
+	src := r.demandSource(cr)
+	if src == nil {
+		cr.Status.Demand = nil
+		return floor
+	}
+
+	st := &gpuv1alpha1.DemandStatus{Source: src.Name()}
+	nodes, err := src.Nodes(ctx)
+	if err != nil {
+		log.Error(err, "Demand estimate failed; using targetCount", "source", src.Name())
+		st.Error = err.Error()
+		st.TargetCount = floor
+	} else {
+		st.Nodes = nodes
+		st.TargetCount = demand.TargetCount(nodes, floor, cr.Spec.Demand.MaxTargetCount)
+	}
+	// Only a changed estimate is stamped so unchanged reconciles do not
+	// rewrite the status.
+	if prev := cr.Status.Demand; prev != nil {
+		st.LastEstimateTime = prev.LastEstimateTime
+		if *prev != *st {
+			st.LastEstimateTime = metav1.Now()
+		}
+	} else {
+		st.LastEstimateTime = metav1.Now()
+	}
+	cr.Status.Demand = st
+	return st.TargetCount
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+)
+
+func TestTargetCountStampsChangedEstimates(t *testing.T) {
+	r := &LeftoverNodePoolReconciler{}
+	cr := &gpuv1alpha1.LeftoverNodePool{Spec: gpuv1alpha1.LeftoverNodePoolSpec{
+		TargetCount: 2,
+		Demand:      &gpuv1alpha1.DemandSource{Type: gpuv1alpha1.DemandPrometheus, Query: "up"},
+	}}
+	// Without a Prometheus client the estimate fails and falls back to
+	// targetCount, the same result on every reconcile.
+	if got := r.targetCount(t.Context(), logr.Discard(), cr); got != 2 {
+		t.Fatalf("targetCount = %d, want 2", got)
+	}
+	old := metav1.NewTime(time.Unix(1_700_000_000, 0))
+	cr.Status.Demand.LastEstimateTime = old
+
+	r.targetCount(t.Context(), logr.Discard(), cr)
+	if !cr.Status.Demand.LastEstimateTime.Equal(&old) {
+		t.Fatalf("unchanged estimate restamped: %v", cr.Status.Demand.LastEstimateTime)
+	}
+
+	cr.Spec.TargetCount = 3
+	r.targetCount(t.Context(), logr.Discard(), cr)
+	if cr.Status.Demand.LastEstimateTime.Equal(&old) {
+		t.Fatal("changed estimate kept the old time")
+	}
+}
@@ -18,15 +18,19 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,7 +38,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/advisor"
 	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/demand"
+	"github.com/devplatformsolutions/leftover/internal/history"
 	"github.com/devplatformsolutions/leftover/internal/karpenterx"
 )
 
@@ -43,13 +50,30 @@ type LeftoverNodePoolReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	AWSFactory *awsx.Factory
+	// History records observed prices; nil disables history-based strategies.
+	History *history.Store
+	// Interruptions provides interruption-frequency buckets per instance type.
+	Interruptions *advisor.Provider
+	// Karpenter detects the Karpenter API version NodePools are rendered for.
+	Karpenter *karpenterx.Detector
+	// Recorder emits events, e.g. on NodePool ownership conflicts; may be nil.
+	Recorder record.EventRecorder
+	// Prometheus runs spec.demand queries; nil when --prometheus-url is unset.
+	Prometheus *demand.PrometheusClient
 }
 
 // +kubebuilder:rbac:groups=gpu.devplatforms.io,resources=leftovernodepools,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gpu.devplatforms.io,resources=leftovernodepools/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=gpu.devplatforms.io,resources=leftovernodepools/finalizers,verbs=update
-// +kubebuilder:rbac:groups=karpenter.k8s.aws,resources=ec2nodeclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gpu.devplatforms.io,resources=spotmarkets,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+// +kubebuilder:rbac:groups=karpenter.k8s.aws,resources=ec2nodeclasses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=eks.amazonaws.com,resources=nodeclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=karpenter.sh,resources=nodepools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=karpenter.sh,resources=nodeoverlays,verbs=get;list;watch;create;update;patch;delete
 
 func (r *LeftoverNodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx).WithValues("leftovernodepool", req.NamespacedName)
@@ -68,113 +92,629 @@ func (r *LeftoverNodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if requeue <= 0 {
 		requeue = 7 * time.Minute
 	}
+	// Reconcile when a schedule window opens or closes, when the next change
+	// window opens to apply a held selection, when a selection awaiting
+	// approval expires, and when the pin expires.
+	boundaries := []time.Time{nextScheduleChange(log, &cr, time.Now())}
+	if p := cr.Status.PendingSelection; p != nil && p.NextWindow != nil {
+		boundaries = append(boundaries, p.NextWindow.Time)
+	}
+	if p := cr.Status.PendingSelection; p != nil && p.ExpiresAt != nil {
+		boundaries = append(boundaries, p.ExpiresAt.Time)
+	}
+	if p := cr.Spec.Pin; p != nil && p.ExpiresAt != nil && p.ExpiresAt.After(time.Now()) {
+		boundaries = append(boundaries, p.ExpiresAt.Time)
+	}
+	for _, t := range boundaries {
+		if t.IsZero() {
+			continue
+		}
+		if until := time.Until(t) + time.Second; until < requeue {
+			requeue = max(until, time.Second)
+		}
+	}
 	log.Info("Requeue scheduled", "after", requeue.String())
 	return ctrl.Result{RequeueAfter: requeue}, nil
 }
 
 func (r *LeftoverNodePoolReconciler) reconcileOnce(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool) error {
 	origStatus := cr.Status.DeepCopy()
+	applySchedules(log, cr, time.Now())
 
-	// Resolve EC2NodeClass
-	nodeClassName, err := karpenterx.ResolveNodeClassName(ctx, r.Client, log, cr.Spec.NodeClassName, cr.Spec.NodeClassSelector)
-	if err != nil {
-		log.Error(err, "Failed to resolve EC2NodeClass")
+	if cr.Spec.Paused {
+		log.Info("Paused; skipping selection and NodePool updates")
 		r.setConditionNoWrite(cr, metav1.Condition{
-			Type:               gpuv1alpha1.ConditionReady,
-			Status:             metav1.ConditionFalse,
-			Reason:             "InvalidSpec",
-			Message:            err.Error(),
+			Type:               gpuv1alpha1.ConditionPaused,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Paused",
+			Message:            "spec.paused is set; AWS is not queried and the NodePool is not updated",
 			ObservedGeneration: cr.GetGeneration(),
 		})
 		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
 	}
+	r.setConditionNoWrite(cr, metav1.Condition{
+		Type:               gpuv1alpha1.ConditionPaused,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Active",
+		Message:            "Selections are applied",
+		ObservedGeneration: cr.GetGeneration(),
+	})
 
-	awsCli, err := r.AWSFactory.ForRegion(ctx, cr.Spec.Region)
-	if err != nil {
-		log.Error(err, "aws client init failed", "region", cr.Spec.Region)
+	if err := r.reconcileSelection(ctx, log, cr); err != nil {
+		reason := "ReconcileError"
+		var ce *conditionError
+		if errors.As(err, &ce) {
+			reason = ce.reason
+		}
+		log.Error(err, "Reconcile failed", "reason", reason)
 		r.setConditionNoWrite(cr, metav1.Condition{
 			Type:               gpuv1alpha1.ConditionReady,
 			Status:             metav1.ConditionFalse,
-			Reason:             "AWSClientError",
+			Reason:             reason,
 			Message:            err.Error(),
 			ObservedGeneration: cr.GetGeneration(),
 		})
 		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
 	}
 
-	types, _, err := awsCli.ListGPUInstanceTypes(ctx, cr.Spec.Families, cr.Spec.MinGPUs)
+	r.setConditionNoWrite(cr, metav1.Condition{
+		Type:               gpuv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Reconciled",
+		Message:            readyMessage(cr),
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	return r.updateStatusIfChanged(ctx, log, cr, origStatus)
+}
+
+// conditionError is a reconcile failure reported as Ready=False with reason.
+type conditionError struct {
+	reason string
+	err    error
+}
+
+func (e *conditionError) Error() string { return e.err.Error() }
+func (e *conditionError) Unwrap() error { return e.err }
+
+func withReason(reason string, err error) error {
+	return &conditionError{reason: reason, err: err}
+}
+
+// market is the market data a selection is made from.
+type market struct {
+	cli     *awsx.Client
+	product string
+	meta    map[string]awsx.InstanceMeta
+	quotes  map[[2]string]awsx.SpotQuote
+	// requirements the candidates were discovered by, if any.
+	requirements *awsx.InstanceRequirements
+	// scorer holds the placement scores fetched by selectOfferings.
+	scorer *awsx.QuoteScorer
+}
+
+// reconcileSelection resolves the node class, collects the market, selects an
+// offering and applies the NodePool. Failures carry their condition reason.
+func (r *LeftoverNodePoolReconciler) reconcileSelection(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool) error {
+	api, err := r.karpenterAPI(cr)
 	if err != nil {
-		log.Error(err, "listing GPU instance types failed")
-		r.setConditionNoWrite(cr, metav1.Condition{
-			Type:               gpuv1alpha1.ConditionReady,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ListTypesError",
-			Message:            err.Error(),
-			ObservedGeneration: cr.GetGeneration(),
-		})
-		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
+		return err
 	}
-	log.Info("Candidate instance types", "count", len(types))
+	cr.Status.KarpenterAPIVersion = api.Version
 
-	quotes, err := awsCli.LatestSpotPrices(ctx, types, 10*time.Minute)
+	adopted, err := r.adoptedNodePool(ctx, cr, api)
 	if err != nil {
-		r.setConditionNoWrite(cr, metav1.Condition{
-			Type:               gpuv1alpha1.ConditionReady,
-			Status:             metav1.ConditionFalse,
-			Reason:             "SpotPriceError",
-			Message:            err.Error(),
-			ObservedGeneration: cr.GetGeneration(),
-		})
-		log.Error(err, "Failed to get latest spot prices")
-		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
+		return err
 	}
-	log.Info("Collected latest spot quotes", "count", len(quotes))
 
-	targetCount := int32(1)
-	if cr.Spec.TargetCount > 0 {
-		targetCount = cr.Spec.TargetCount
+	classes, err := r.nodeClassesFor(ctx, log, cr, api, adopted)
+	if err != nil {
+		return err
 	}
-	scorer, err := awsx.NewQuoteScorer(ctx, awsCli, types, targetCount)
+
+	m, err := r.collectMarket(ctx, log, cr, classes.Primary())
 	if err != nil {
-		r.setConditionNoWrite(cr, metav1.Condition{
-			Type:               gpuv1alpha1.ConditionReady,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ScorerError",
-			Message:            err.Error(),
-			ObservedGeneration: cr.GetGeneration(),
+		return err
+	}
+
+	choices, selectErr := r.selectOfferings(ctx, log, cr, m, classes, adopted)
+	choices, pinned, err := r.applyPin(ctx, log, cr, m, classes, adopted, choices)
+	if err != nil {
+		return err
+	}
+	if selectErr != nil {
+		// A failed selection does not stop an active pin.
+		if !pinned {
+			return selectErr
+		}
+		log.Error(selectErr, "Selection failed; applying pin")
+	}
+	if isShadow(cr) {
+		return r.recordShadow(ctx, log, cr, m, choices)
+	}
+	cr.Status.Shadow = nil
+	approved := false
+	if !pinned {
+		choices, approved = r.gateSelection(ctx, log, cr, m, classes, choices)
+	}
+
+	overlay := cr.Spec.OutputMode == gpuv1alpha1.OutputNodeOverlay && !pinned
+	switch {
+	case cr.Spec.Diversification != nil && !pinned:
+		err = r.applyDiversified(ctx, log, cr, api, m, choices)
+	case overlay:
+		err = r.applyPriceHints(ctx, log, cr, api, adopted, m, choices)
+		// Karpenter picks among the hinted offerings; the best one is reported.
+		choices = choices[:1]
+	default:
+		err = r.applyNodePool(ctx, log, cr, api, adopted, nodePoolParams(cr, m, choices[0]), choices[0].offering())
+	}
+	if err != nil {
+		return err
+	}
+	if !overlay {
+		r.clearPriceHints(ctx, log, cr, api)
+	}
+	if approved {
+		r.finishApproval(ctx, log, cr)
+	}
+	r.recordSelection(ctx, cr, m, choices)
+	return nil
+}
+
+// ownedNodePoolName is the NodePool (and rendered EC2NodeClass) created for cr.
+func ownedNodePoolName(cr *gpuv1alpha1.LeftoverNodePool) string {
+	return fmt.Sprintf("leftover-%s", cr.Name)
+}
+
+// adoptedNodePool reads the NodePool of spec.nodePoolRef, or returns nil.
+func (r *LeftoverNodePoolReconciler) adoptedNodePool(ctx context.Context, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API) (*karpenterx.AdoptedNodePool, error) {
+	ref := cr.Spec.NodePoolRef
+	if ref == nil {
+		return nil, nil
+	}
+	adopted, err := api.GetAdoptedNodePool(ctx, r.Client, ref.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, withReason("NodePoolNotFound", err)
+	}
+	if err != nil {
+		return nil, withReason("AdoptNodePoolError", err)
+	}
+	return adopted, nil
+}
+
+// applyNodePool applies the selection to the owned or adopted NodePool
+// following spec.rolloutStrategy, reports ownership conflicts and cleans up
+// objects a previous selection or spec created.
+func (r *LeftoverNodePoolReconciler) applyNodePool(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API, adopted *karpenterx.AdoptedNodePool, params karpenterx.NodePoolParams, best karpenterx.Offering) error {
+	if adopted != nil {
+		params.Name = adopted.Name
+	}
+	rollout, err := r.planRollout(ctx, cr, &params, best)
+	if err != nil {
+		return err
+	}
+	active := map[string]bool{params.Name: true}
+	pools := r.ownedNodePools(ctx, log, cr, api)
+	r.releaseNodePools(ctx, log, api, pools, active)
+
+	conflict, err := api.UpsertNodePool(ctx, r.Client, applyOptions(cr, adopted), params, cr)
+	if err := r.nodePoolApplied(cr, conflict, err); err != nil {
+		return err
+	}
+	log.Info("Upserted NodePool", "name", params.Name, "nodeClass", params.NodeClassName, "adopted", adopted != nil, "rollout", rollout.Strategy)
+	cr.Status.NodePoolName = params.Name
+	cr.Status.NodeClassName = params.NodeClassName
+	cr.Status.DiversifiedNodePools = nil
+
+	// NodePools of earlier selections, or created before an adoption, drain first.
+	r.finishRollout(ctx, log, cr, api, pools, active, rollout)
+	r.deleteUnusedNodeClass(ctx, log, cr, api)
+	return nil
+}
+
+// applyDiversified applies one weighted NodePool per selected offering, with
+// its share of spec.diversification.limits, and drains the NodePools of
+// offerings no longer selected.
+func (r *LeftoverNodePoolReconciler) applyDiversified(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API, m *market, choices []choice) error {
+	div := cr.Spec.Diversification
+	total := div.Limits
+	if l := scheduledLimits(cr); l != nil {
+		total = l
+	}
+	limits := karpenterx.SplitLimits(total, div.Allocation, len(choices))
+	all := make([]karpenterx.NodePoolParams, 0, len(choices))
+	active := make(map[string]bool, len(choices))
+	for i, c := range choices {
+		params := nodePoolParams(cr, m, c)
+		params.Name = selectionNodePoolName(cr, params)
+		params.Weight = karpenterx.RankWeight(i, len(choices))
+		params.Limits = limits[i]
+		all = append(all, params)
+		active[params.Name] = true
+	}
+	pools := r.ownedNodePools(ctx, log, cr, api)
+	r.releaseNodePools(ctx, log, api, pools, active)
+
+	opts := applyOptions(cr, nil)
+	selected := make([]gpuv1alpha1.DiversifiedNodePool, 0, len(all))
+	var conflict *karpenterx.ConflictError
+	for i, params := range all {
+		c, err := api.UpsertNodePool(ctx, r.Client, opts, params, cr)
+		if conflict == nil {
+			conflict = c
+		}
+		if err != nil {
+			return r.nodePoolApplied(cr, conflict, err)
+		}
+		log.Info("Upserted diversified NodePool", "name", params.Name, "rank", i+1, "instanceType", params.InstanceTypes[0], "zone", params.Zones[0], "weight", params.Weight)
+		selected = append(selected, gpuv1alpha1.DiversifiedNodePool{
+			Name:          params.Name,
+			InstanceType:  params.InstanceTypes[0],
+			Zone:          params.Zones[0],
+			NodeClassName: params.NodeClassName,
+			Weight:        params.Weight,
+			Limits:        params.Limits,
 		})
-		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
 	}
+	if err := r.nodePoolApplied(cr, conflict, nil); err != nil {
+		return err
+	}
+	cr.Status.NodePoolName = all[0].Name
+	cr.Status.NodeClassName = all[0].NodeClassName
+	cr.Status.DiversifiedNodePools = selected
 
-	threshold := cr.Spec.MinSpotScore
-	best, score, ok, err := scorer.PickCheapestInBatches(ctx, quotes, 5, threshold)
+	r.finishRollout(ctx, log, cr, api, pools, active, &gpuv1alpha1.RolloutStatus{Strategy: rolloutType(cr)})
+	r.deleteUnusedNodeClass(ctx, log, cr, api)
+	return nil
+}
+
+// nodePoolParams renders the NodePool of one selected offering; the name is
+// the owned NodePool's until a rollout or diversification renames it.
+func nodePoolParams(cr *gpuv1alpha1.LeftoverNodePool, m *market, c choice) karpenterx.NodePoolParams {
+	params := karpenterx.NodePoolParams{
+		Name:          ownedNodePoolName(cr),
+		NodeClassName: c.nodeClass.Name,
+		InstanceTypes: []string{c.quote.InstanceType},
+		Zones:         []string{c.quote.Zone},
+		CapacityType:  cr.Spec.CapacityType,
+		Limits:        scheduledLimits(cr),
+	}
+	if archs := m.meta[c.quote.InstanceType].Architectures; len(archs) > 0 {
+		params.Architectures = archs[:1]
+	}
+	if cr.Spec.RequirementMode == gpuv1alpha1.RequirementsAttributes {
+		params.AddAttributes(karpenterx.InstanceLabels(m.meta[c.quote.InstanceType]))
+	}
+	return params
+}
+
+func applyOptions(cr *gpuv1alpha1.LeftoverNodePool, adopted *karpenterx.AdoptedNodePool) karpenterx.ApplyOptions {
+	return karpenterx.ApplyOptions{
+		FieldOwner: "leftover",
+		Force:      cr.Spec.ForceOwnership == nil || *cr.Spec.ForceOwnership,
+		Adopt:      adopted != nil,
+	}
+}
+
+// nodePoolApplied reports the outcome of UpsertNodePool and maps its error to
+// a condition reason.
+func (r *LeftoverNodePoolReconciler) nodePoolApplied(cr *gpuv1alpha1.LeftoverNodePool, conflict *karpenterx.ConflictError, err error) error {
+	r.reportConflict(cr, conflict, cr.Spec.ForceOwnership == nil || *cr.Spec.ForceOwnership)
+	var ce *karpenterx.ConflictError
+	if errors.As(err, &ce) {
+		return withReason("OwnershipConflict", err)
+	}
 	if err != nil {
+		return withReason("ApplyNodePoolError", err)
+	}
+	return nil
+}
+
+// deleteUnusedNodeClass deletes the EC2NodeClass rendered from an earlier
+// nodeClassTemplate once the spec no longer has one.
+func (r *LeftoverNodePoolReconciler) deleteUnusedNodeClass(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API) {
+	if cr.Spec.NodeClassTemplate != nil {
+		return
+	}
+	name := ownedNodePoolName(cr)
+	if err := api.DeleteOwnedNodeClass(ctx, r.Client, name, cr); err != nil {
+		log.Error(err, "deleting previously rendered EC2NodeClass failed", "name", name)
+	}
+}
+
+// reportConflict sets the OwnershipConflict condition and emits a warning
+// event while another field manager changes the NodePool requirements.
+func (r *LeftoverNodePoolReconciler) reportConflict(cr *gpuv1alpha1.LeftoverNodePool, conflict *karpenterx.ConflictError, forced bool) {
+	if conflict == nil {
 		r.setConditionNoWrite(cr, metav1.Condition{
-			Type:               gpuv1alpha1.ConditionReady,
+			Type:               gpuv1alpha1.ConditionOwnershipConflict,
 			Status:             metav1.ConditionFalse,
-			Reason:             "SelectionError",
-			Message:            err.Error(),
+			Reason:             "NoConflict",
+			Message:            "Leftover owns the NodePool requirements",
 			ObservedGeneration: cr.GetGeneration(),
 		})
-		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
+		return
 	}
-	if best == nil {
-		r.setConditionNoWrite(cr, metav1.Condition{
-			Type:               gpuv1alpha1.ConditionReady,
-			Status:             metav1.ConditionFalse,
-			Reason:             "NoQuotes",
-			Message:            "no spot quotes available",
-			ObservedGeneration: cr.GetGeneration(),
+	msg := conflict.Error()
+	if forced {
+		msg += "; ownership was taken back"
+	} else {
+		msg += "; the NodePool was left unchanged (forceOwnership is false)"
+	}
+	r.setConditionNoWrite(cr, metav1.Condition{
+		Type:               gpuv1alpha1.ConditionOwnershipConflict,
+		Status:             metav1.ConditionTrue,
+		Reason:             "FieldManagerConflict",
+		Message:            msg,
+		ObservedGeneration: cr.GetGeneration(),
+	})
+	if r.Recorder != nil {
+		r.Recorder.Event(cr, corev1.EventTypeWarning, "OwnershipConflict", msg)
+	}
+}
+
+// karpenterAPI returns the served Karpenter API bound to the node class kind
+// of spec.nodeClassRef.
+func (r *LeftoverNodePoolReconciler) karpenterAPI(cr *gpuv1alpha1.LeftoverNodePool) (*karpenterx.API, error) {
+	api, err := r.Karpenter.API()
+	if err == nil {
+		kind := karpenterx.EC2NodeClass
+		if ref := cr.Spec.NodeClassRef; ref != nil && ref.Kind == gpuv1alpha1.NodeClassKindAutoMode {
+			kind = karpenterx.AutoModeNodeClass
+		}
+		api, err = api.ForNodeClass(kind)
+	}
+	if errors.Is(err, karpenterx.ErrAPIUnsupported) {
+		return nil, withReason(karpenterx.ReasonAPIUnsupported, err)
+	}
+	if err != nil {
+		return nil, withReason("KarpenterDiscoveryError", err)
+	}
+	return api, nil
+}
+
+// nodeClassesFor resolves the EC2NodeClasses candidates may use (see
+// resolveNodeClass and spec.nodeClassMappings, or the class of the adopted
+// NodePool). When every candidate uses the
+// same class, a class that is not Ready fails the reconcile before any
+// market data is fetched.
+func (r *LeftoverNodePoolReconciler) nodeClassesFor(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API, adopted *karpenterx.AdoptedNodePool) (*karpenterx.NodeClasses, error) {
+	fallback := karpenterx.NodeClassSource{}
+	if adopted != nil {
+		fallback.Name = adopted.NodeClassName
+	} else {
+		var err error
+		if fallback, err = r.resolveNodeClass(ctx, log, cr, api, ownedNodePoolName(cr)); err != nil {
+			return nil, withReason("ApplyNodeClassError", err)
+		}
+	}
+	routes := make([]karpenterx.NodeClassRoute, 0, len(cr.Spec.NodeClassMappings))
+	for _, m := range cr.Spec.NodeClassMappings {
+		routes = append(routes, karpenterx.NodeClassRoute{
+			Patterns: m.Patterns,
+			Source:   karpenterx.NodeClassSource{Name: m.NodeClassName, Selector: m.NodeClassSelector},
 		})
-		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
 	}
+	classes, err := api.LoadNodeClasses(ctx, r.Client, routes, fallback)
+	if err != nil {
+		return nil, withReason("InvalidSpec", err)
+	}
+	if only := classes.Only(); only != nil {
+		if perr := only.CheckReady(); perr != nil {
+			return nil, withReason(perr.Reason, perr)
+		}
+	}
+	return classes, nil
+}
+
+// collectMarket gathers the quotes for the CR's GPU types, priced for the node
+// class operating system and filtered by maxInterruptionRate.
+func (r *LeftoverNodePoolReconciler) collectMarket(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, nodeClass *karpenterx.NodeClassStatus) (*market, error) {
+	awsCli, err := r.AWSFactory.ForRegion(ctx, cr.Spec.Region)
+	if err != nil {
+		return nil, withReason("AWSClientError", err)
+	}
+
+	product := r.resolveProduct(ctx, log, cr, nodeClass, awsCli)
+	cr.Status.ProductDescription = product
 
-	if ok {
-		log.Info("Selected quote", "instanceType", best.InstanceType, "zone", best.Zone, "priceUSD", best.PriceUSD, "score", score, "timestamp", best.Timestamp.Format(time.RFC3339))
+	if name, err := ensureSpotMarket(ctx, r.Client, cr.Spec.Region, product); err != nil {
+		// The market snapshot is informational; selection does not depend on it.
+		log.Error(err, "ensuring SpotMarket failed", "region", cr.Spec.Region)
 	} else {
-		log.Info("No quote met score threshold; using cheapest", "threshold", threshold, "instanceType", best.InstanceType, "zone", best.Zone, "priceUSD", best.PriceUSD, "score", score)
+		cr.Status.SpotMarket = name
+	}
+
+	req := instanceRequirements(cr.Spec.InstanceRequirements)
+	var types []string
+	var metaByType map[string]awsx.InstanceMeta
+	if req != nil {
+		types, metaByType, err = awsCli.ListInstanceTypesByRequirements(ctx, *req, cr.Spec.Families, cr.Spec.MinGPUs)
+	} else {
+		types, metaByType, err = awsCli.ListGPUInstanceTypes(ctx, cr.Spec.Families, cr.Spec.MinGPUs)
+	}
+	if err != nil {
+		return nil, withReason("ListTypesError", err)
+	}
+	log.Info("Candidate instance types", "count", len(types))
+
+	quotes, err := awsCli.LatestSpotPrices(ctx, product, types, 10*time.Minute)
+	if err != nil {
+		return nil, withReason("SpotPriceError", err)
+	}
+	log.Info("Collected latest spot quotes", "count", len(quotes))
+
+	if r.History != nil {
+		if err := r.History.Observe(ctx, awsCli, cr.Spec.Region, product, quotes); err != nil {
+			// Strategies fall back to current prices for offerings without history.
+			log.Error(err, "recording price history failed")
+		}
+	}
+
+	if maxRate := cr.Spec.MaxInterruptionRate; maxRate != "" {
+		quotes = r.withinInterruptionRate(ctx, cr.Spec.Region, product, maxRate, quotes)
+		if len(quotes) == 0 {
+			return nil, withReason("InterruptionRateExceeded", fmt.Errorf("no spot offering has an interruption rate within %s", maxRate))
+		}
+		log.Info("Quotes within interruption rate", "max", maxRate, "count", len(quotes))
+	}
+
+	return &market{cli: awsCli, product: product, meta: metaByType, quotes: quotes, requirements: req}, nil
+}
+
+// instanceRequirements converts spec.instanceRequirements, or returns nil.
+func instanceRequirements(ir *gpuv1alpha1.InstanceRequirements) *awsx.InstanceRequirements {
+	if ir == nil {
+		return nil
+	}
+	toRange := func(r *gpuv1alpha1.IntRange) awsx.Range {
+		if r == nil {
+			return awsx.Range{}
+		}
+		return awsx.Range{Min: r.Min, Max: r.Max}
+	}
+	return &awsx.InstanceRequirements{
+		AcceleratorTypes:          ir.AcceleratorTypes,
+		AcceleratorManufacturers:  ir.AcceleratorManufacturers,
+		AcceleratorNames:          ir.AcceleratorNames,
+		AcceleratorCount:          toRange(ir.AcceleratorCount),
+		AcceleratorTotalMemoryMiB: toRange(ir.AcceleratorTotalMemoryMiB),
+		VCPUCount:                 toRange(ir.VCPUCount),
+		MemoryMiB:                 toRange(ir.MemoryMiB),
+		ExcludedInstanceTypes:     ir.ExcludedInstanceTypes,
+	}
+}
+
+// choice is a selected offering with the node class that launches it.
+type choice struct {
+	quote     awsx.SpotQuote
+	nodeClass *karpenterx.NodeClassStatus
+	score     int32
+}
+
+func (c choice) offering() karpenterx.Offering {
+	return karpenterx.Offering{InstanceType: c.quote.InstanceType, Zone: c.quote.Zone}
+}
+
+// selectOfferings scores the quotes and picks the best one the node class can
+// launch, the best spec.diversification.pools ones, or every launchable one
+// for NodeOverlay output, in rank order.
+// Candidates failing the preflight are skipped; the first rejection explains
+// the failure if no candidate is left.
+func (r *LeftoverNodePoolReconciler) selectOfferings(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, m *market, classes *karpenterx.NodeClasses, adopted *karpenterx.AdoptedNodePool) ([]choice, error) {
+	scorer, err := newScorer(ctx, m, r.targetCount(ctx, log, cr))
+	if err != nil {
+		return nil, withReason("ScorerError", err)
+	}
+	m.scorer = scorer
+	if stale, missing := scorer.Degraded(); stale+missing > 0 {
+		log.Info("Placement score budget exhausted; using last known scores", "staleTypes", stale, "unscoredTypes", missing)
+	}
+
+	fit, err := r.workloadFit(ctx, log, cr, m)
+	if err != nil {
+		return nil, withReason("WorkloadError", err)
+	}
+
+	var rejected *karpenterx.PreflightError
+	rejections := 0
+	accept := func(q awsx.SpotQuote) bool {
+		if fit != nil && !fit.fits(q.InstanceType) {
+			return false
+		}
+		perr := preflight(classes, adopted, m.meta[q.InstanceType], q)
+		if perr == nil {
+			return true
+		}
+		if rejected == nil {
+			rejected = perr
+		}
+		rejections++
+		log.V(1).Info("Preflight rejected candidate", "instanceType", q.InstanceType, "zone", q.Zone, "reason", perr.Reason)
+		return false
+	}
+
+	threshold := cr.Spec.MinSpotScore
+	cost := r.rankingCost(ctx, cr.Spec.Region, m.product, cr.Spec.Strategy)
+	if fit != nil {
+		cost = fit.fleetCost(cost)
+	}
+	n := 1
+	if div := cr.Spec.Diversification; div != nil {
+		n = div.Pools
+	} else if cr.Spec.OutputMode == gpuv1alpha1.OutputNodeOverlay {
+		n = len(m.quotes)
+	}
+	top, err := scorer.PickTopInBatches(ctx, m.quotes, 5, n, threshold, cost, accept)
+	if err != nil {
+		return nil, withReason("SelectionError", err)
+	}
+	if len(top) == 0 {
+		return nil, noCandidates(fit, rejected, rejections)
+	}
+
+	if rejections > 0 {
+		log.Info("Preflight skipped candidates the node class cannot launch", "skipped", rejections, "firstReason", rejected.Reason)
+	}
+	choices := make([]choice, 0, len(top))
+	for _, q := range top {
+		if q.MeetsThreshold {
+			log.Info("Selected quote", "instanceType", q.InstanceType, "zone", q.Zone, "priceUSD", q.PriceUSD, "score", q.Score, "timestamp", q.Timestamp.Format(time.RFC3339))
+		} else {
+			log.Info("No quote met score threshold; using cheapest", "threshold", threshold, "instanceType", q.InstanceType, "zone", q.Zone, "priceUSD", q.PriceUSD, "score", q.Score)
+		}
+		// Selected quotes passed the preflight, so their class resolves.
+		nodeClass, _ := classes.For(q.InstanceType)
+		choices = append(choices, choice{quote: q.SpotQuote, nodeClass: nodeClass, score: q.Score})
+	}
+	if fit != nil {
+		fit.record(cr, m, choices[0].quote, cost)
+	}
+	logCheapestQuotes(ctx, log, scorer, m.quotes)
+	return choices, nil
+}
+
+// newScorer scores only types that have quotes, cheapest first, so a tight
+// placement score budget is spent on the types most likely to be selected.
+func newScorer(ctx context.Context, m *market, targetCount int32) (*awsx.QuoteScorer, error) {
+	if m.requirements != nil {
+		return awsx.NewRequirementsScorer(ctx, m.cli, *m.requirements, awsx.TypesByPrice(m.quotes), targetCount)
 	}
+	return awsx.NewQuoteScorer(ctx, m.cli, awsx.TypesByPrice(m.quotes), targetCount)
+}
 
+// noCandidates explains why no quote was selected: the first preflight
+// rejection, else a workload no candidate fits, else missing quotes.
+func noCandidates(fit *fitPlan, rejected *karpenterx.PreflightError, rejections int) error {
+	if rejected != nil {
+		return withReason(rejected.Reason, fmt.Errorf("%s (all %d candidates failed preflight)", rejected.Message, rejections))
+	}
+	if fit != nil && fit.unfit() > 0 {
+		return withReason("WorkloadDoesNotFit", fmt.Errorf("a pod exceeds every candidate instance type (%d types too small)", fit.unfit()))
+	}
+	return withReason("NoQuotes", errors.New("no spot quotes available"))
+}
+
+// preflight resolves the class of q's instance type and checks that it can
+// launch q and, for an adopted NodePool, that its own requirements allow q.
+func preflight(classes *karpenterx.NodeClasses, adopted *karpenterx.AdoptedNodePool, meta awsx.InstanceMeta, q awsx.SpotQuote) *karpenterx.PreflightError {
+	labels := karpenterx.InstanceLabels(meta)
+	if adopted != nil {
+		if perr := adopted.Check(labels); perr != nil {
+			return perr
+		}
+	}
+	nodeClass, perr := classes.For(q.InstanceType)
+	if perr != nil {
+		return perr
+	}
+	if perr := nodeClass.CheckReady(); perr != nil {
+		return perr
+	}
+	return nodeClass.Check(labels, q.Zone)
+}
+
+func logCheapestQuotes(ctx context.Context, log logr.Logger, scorer *awsx.QuoteScorer, quotes map[[2]string]awsx.SpotQuote) {
 	entries := make([]awsx.SpotQuote, 0, len(quotes))
 	for _, q := range quotes {
 		entries = append(entries, q)
@@ -187,36 +727,18 @@ func (r *LeftoverNodePoolReconciler) reconcileOnce(ctx context.Context, log logr
 		s, _ := scorer.ScoreFor(ctx, q.InstanceType, q.Zone)
 		log.Info("Quote", "rank", i+1, "instanceType", q.InstanceType, "zone", q.Zone, "priceUSD", q.PriceUSD, "score", s, "timestamp", q.Timestamp.Format(time.RFC3339))
 	}
+}
 
-	capacityType := cr.Spec.CapacityType
-	if capacityType == "" {
-		capacityType = "spot"
-	}
-	poolName := fmt.Sprintf("leftover-%s", cr.Name)
-	if err := karpenterx.UpsertNodePool(
-		ctx,
-		r.Client,
-		"leftover",
-		poolName,
-		nodeClassName,
-		best.InstanceType,
-		best.Zone,
-		capacityType,
-		cr,
-	); err != nil {
-		r.setConditionNoWrite(cr, metav1.Condition{
-			Type:               gpuv1alpha1.ConditionReady,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ApplyNodePoolError",
-			Message:            err.Error(),
-			ObservedGeneration: cr.GetGeneration(),
-		})
-		return r.updateStatusIfChanged(ctx, log, cr, origStatus)
+// recordSelection writes the applied selection to the status. Price, score
+// and statistics are those of the best choice.
+func (r *LeftoverNodePoolReconciler) recordSelection(ctx context.Context, cr *gpuv1alpha1.LeftoverNodePool, m *market, choices []choice) {
+	best, score := choices[0].quote, choices[0].score
+	newInstanceTypes := make([]string, 0, len(choices))
+	newZones := make([]string, 0, len(choices))
+	for _, c := range choices {
+		newInstanceTypes = append(newInstanceTypes, c.quote.InstanceType)
+		newZones = append(newZones, c.quote.Zone)
 	}
-	log.Info("Upserted NodePool", "name", poolName, "nodeClass", nodeClassName)
-
-	newInstanceTypes := []string{best.InstanceType}
-	newZones := []string{best.Zone}
 	priceStr := fmt.Sprintf("%.4f", best.PriceUSD)
 
 	selectionChanged := !reflect.DeepEqual(cr.Status.SelectedInstanceTypes, newInstanceTypes) ||
@@ -228,19 +750,148 @@ func (r *LeftoverNodePoolReconciler) reconcileOnce(ctx context.Context, log logr
 	cr.Status.SelectedZones = newZones
 	cr.Status.LastPriceUSD = priceStr
 	cr.Status.LastScore = int(score)
+	cr.Status.PriceStats = r.priceStats(ctx, cr.Spec.Region, m.product, cr.Spec.Strategy, best)
+	rate, known := r.Interruptions.Rate(ctx, cr.Spec.Region, advisor.OSForProduct(m.product), best.InstanceType)
+	cr.Status.InterruptionRate = rate.Label
+	if !known && rate.Label != "" {
+		cr.Status.InterruptionRate += " (assumed)"
+	}
 	if selectionChanged {
 		cr.Status.LastSyncTime = metav1.Now()
 	}
+}
 
-	r.setConditionNoWrite(cr, metav1.Condition{
-		Type:               gpuv1alpha1.ConditionReady,
-		Status:             metav1.ConditionTrue,
-		Reason:             "Reconciled",
-		Message:            "NodePool updated",
-		ObservedGeneration: cr.GetGeneration(),
-	})
+// resolveNodeClass returns the node class source from the spec: the EC2NodeClass
+// rendered from spec.nodeClassTemplate (applied here), or spec.nodeClassName or
+// spec.nodeClassSelector.
+func (r *LeftoverNodePoolReconciler) resolveNodeClass(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API, name string) (karpenterx.NodeClassSource, error) {
+	tmpl := cr.Spec.NodeClassTemplate
+	if tmpl == nil {
+		return karpenterx.NodeClassSource{Name: cr.Spec.NodeClassName, Selector: cr.Spec.NodeClassSelector}, nil
+	}
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tmpl)
+	if err != nil {
+		return karpenterx.NodeClassSource{}, fmt.Errorf("rendering nodeClassTemplate: %w", err)
+	}
+	if err := api.ApplyNodeClass(ctx, r.Client, "leftover", name, spec, cr.Spec.SubnetSelectorTags, cr.Spec.SecurityGroupSelectorTags, cr); err != nil {
+		return karpenterx.NodeClassSource{}, fmt.Errorf("applying EC2NodeClass %q: %w", name, err)
+	}
+	log.Info("Applied EC2NodeClass from template", "name", name)
+	return karpenterx.NodeClassSource{Name: name}, nil
+}
 
-	return r.updateStatusIfChanged(ctx, log, cr, origStatus)
+// resolveProduct returns the Spot price product description for the node
+// class: the spec override, else the OS implied by amiFamily or an AMI alias,
+// else the platform of the AMIs Karpenter resolved. Anything undeterminable is
+// priced as Linux/UNIX.
+func (r *LeftoverNodePoolReconciler) resolveProduct(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, nodeClass *karpenterx.NodeClassStatus, awsCli *awsx.Client) string {
+	if cr.Spec.ProductDescription != "" {
+		return cr.Spec.ProductDescription
+	}
+	if nodeClass == nil {
+		return awsx.ProductLinux
+	}
+	if product, ok := awsx.ProductForAMIFamily(nodeClass.AMIFamily); ok {
+		return product
+	}
+	for _, alias := range nodeClass.AMIAliases {
+		if product, ok := awsx.ProductForAMIFamily(alias); ok {
+			return product
+		}
+	}
+	ids := make([]string, 0, len(nodeClass.AMIs))
+	for _, ami := range nodeClass.AMIs {
+		if ami.ID != "" {
+			ids = append(ids, ami.ID)
+		}
+	}
+	if len(ids) > 0 {
+		product, err := awsCli.ImageProduct(ctx, ids)
+		if err != nil {
+			log.Error(err, "describing EC2NodeClass AMIs failed; pricing as Linux/UNIX", "amis", ids)
+			return awsx.ProductLinux
+		}
+		return product
+	}
+	return awsx.ProductLinux
+}
+
+// rankingCost returns the price offerings are ranked by under strategy, or nil
+// to rank by current price.
+func (r *LeftoverNodePoolReconciler) rankingCost(ctx context.Context, region, product string, strategy *gpuv1alpha1.SelectionStrategy) func(awsx.SpotQuote) float64 {
+	if strategy == nil {
+		return nil
+	}
+	return func(q awsx.SpotQuote) float64 {
+		return r.effectivePrice(ctx, region, product, strategy, q)
+	}
+}
+
+// effectivePrice applies strategy to q: the forecast replaces the current
+// price for ExpectedPrice, then volatility and interruption penalties are added.
+// Offerings without history keep their current price; without interruption
+// data no interruption penalty applies.
+func (r *LeftoverNodePoolReconciler) effectivePrice(ctx context.Context, region, product string, strategy *gpuv1alpha1.SelectionStrategy, q awsx.SpotQuote) float64 {
+	price := q.PriceUSD
+	if strategy == nil {
+		return price
+	}
+	if r.History != nil {
+		if st, ok := r.History.Stats(region, product, q.InstanceType, q.Zone, forecastHours(strategy)); ok {
+			if strategy.Type == gpuv1alpha1.StrategyExpectedPrice {
+				price = st.ForecastUSD
+			}
+			price = st.Penalized(price, strategy.VolatilityPenaltyPercent)
+		}
+	}
+	if strategy.InterruptionPenaltyPercent > 0 {
+		if rate, _ := r.Interruptions.Rate(ctx, region, advisor.OSForProduct(product), q.InstanceType); rate.Label != "" {
+			price *= 1 + float64(strategy.InterruptionPenaltyPercent)/100*float64(rate.Index)
+		}
+	}
+	return price
+}
+
+// withinInterruptionRate drops quotes whose instance type is in a higher
+// interruption bucket than maxLabel; unknown rates pass. The cached quotes map
+// is not modified.
+func (r *LeftoverNodePoolReconciler) withinInterruptionRate(ctx context.Context, region, product, maxLabel string, quotes map[[2]string]awsx.SpotQuote) map[[2]string]awsx.SpotQuote {
+	limit, ok := advisor.RangeByLabel(maxLabel)
+	if !ok {
+		return quotes
+	}
+	out := make(map[[2]string]awsx.SpotQuote, len(quotes))
+	for k, q := range quotes {
+		if rate, _ := r.Interruptions.Rate(ctx, region, advisor.OSForProduct(product), q.InstanceType); rate.Label == "" || rate.Index <= limit.Index {
+			out[k] = q
+		}
+	}
+	return out
+}
+
+func (r *LeftoverNodePoolReconciler) priceStats(ctx context.Context, region, product string, strategy *gpuv1alpha1.SelectionStrategy, q awsx.SpotQuote) *gpuv1alpha1.PriceStats {
+	if r.History == nil {
+		return nil
+	}
+	st, ok := r.History.Stats(region, product, q.InstanceType, q.Zone, forecastHours(strategy))
+	if !ok {
+		return nil
+	}
+	return &gpuv1alpha1.PriceStats{
+		WindowHours:       int(st.Window / time.Hour),
+		MeanUSD:           fmt.Sprintf("%.4f", st.MeanUSD),
+		Volatility:        fmt.Sprintf("%.4f", st.Volatility),
+		TrendPerDay:       fmt.Sprintf("%.4f", st.TrendPerDay),
+		ForecastUSD:       fmt.Sprintf("%.4f", st.ForecastUSD),
+		EffectivePriceUSD: fmt.Sprintf("%.4f", r.effectivePrice(ctx, region, product, strategy, q)),
+	}
+}
+
+func forecastHours(strategy *gpuv1alpha1.SelectionStrategy) int {
+	if strategy == nil || strategy.ForecastHours <= 0 {
+		return history.DefaultForecastHours
+	}
+	return strategy.ForecastHours
 }
 
 func (r *LeftoverNodePoolReconciler) setConditionNoWrite(cr *gpuv1alpha1.LeftoverNodePool, cond metav1.Condition) {
@@ -263,7 +914,13 @@ func (r *LeftoverNodePoolReconciler) updateStatusIfChanged(ctx context.Context,
 
 // SetupWithManager wires the controller into the manager.
 func (r *LeftoverNodePoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// spec.demand and WorkloadFit list pods by phase from the cache.
+	if err := demand.IndexPodPhase(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&gpuv1alpha1.LeftoverNodePool{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Annotation changes carry selection approvals.
+		For(&gpuv1alpha1.LeftoverNodePool{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
 		Complete(r)
 }
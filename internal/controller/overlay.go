@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/karpenterx"
+)
+
+// applyPriceHints applies a NodePool allowing the launchable candidates of
+// the best offering's node class in any zone, and publishes their effective
+// prices as NodeOverlays for Karpenter to choose by.
+func (r *LeftoverNodePoolReconciler) applyPriceHints(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API, adopted *karpenterx.AdoptedNodePool, m *market, choices []choice) error {
+	if !api.NodeOverlays {
+		return withReason(karpenterx.ReasonNodeOverlayUnsupported, karpenterx.ErrNodeOverlayUnsupported)
+	}
+	best := choices[0]
+	params := nodePoolParams(cr, m, best)
+	params.Zones = nil
+	capacityType := params.CapacityType
+	if capacityType == "" {
+		capacityType = "spot"
+	}
+	var onDemand map[string]float64
+	if capacityType == "on-demand" {
+		var err error
+		if onDemand, err = m.cli.OnDemandPrices(ctx, m.product); err != nil {
+			return withReason("OnDemandPriceError", fmt.Errorf("on-demand prices for price hints: %w", err))
+		}
+	}
+	hints := make([]karpenterx.PriceHint, 0, len(choices))
+	for _, c := range choices {
+		// A NodePool references a single node class.
+		if c.nodeClass.Name != best.nodeClass.Name {
+			continue
+		}
+		arch := ""
+		if archs := m.meta[c.quote.InstanceType].Architectures; len(archs) > 0 {
+			arch = archs[0]
+		}
+		params.AddOffering(karpenterx.Offering{InstanceType: c.quote.InstanceType}, arch)
+		price := r.hintPrice(ctx, cr, m, c)
+		if onDemand != nil {
+			// Spot signals do not apply to on-demand capacity; types without a
+			// list price keep Karpenter's own.
+			var ok bool
+			if price, ok = onDemand[c.quote.InstanceType]; !ok || price <= 0 {
+				continue
+			}
+		}
+		hints = append(hints, karpenterx.PriceHint{
+			Offering:     c.offering(),
+			CapacityType: capacityType,
+			PriceUSD:     price,
+		})
+	}
+
+	if err := r.applyNodePool(ctx, log, cr, api, adopted, params, best.offering()); err != nil {
+		return err
+	}
+	if err := api.SyncNodeOverlays(ctx, r.Client, "leftover", cr.Status.NodePoolName, hints, cr); err != nil {
+		return withReason("ApplyNodeOverlayError", err)
+	}
+	log.Info("Published NodeOverlay price hints", "nodePool", cr.Status.NodePoolName, "count", len(hints))
+	cr.Status.NodeOverlays = int32(len(hints))
+	return nil
+}
+
+// hintPrice is the effective Spot price of c (see effectivePrice) plus the
+// placement score penalty of spec.strategy.
+func (r *LeftoverNodePoolReconciler) hintPrice(ctx context.Context, cr *gpuv1alpha1.LeftoverNodePool, m *market, c choice) float64 {
+	strategy := cr.Spec.Strategy
+	price := r.effectivePrice(ctx, cr.Spec.Region, m.product, strategy, c.quote)
+	if strategy != nil && strategy.PlacementPenaltyPercent > 0 && c.score < 10 {
+		price *= 1 + float64(strategy.PlacementPenaltyPercent)/100*float64(10-c.score)
+	}
+	return price
+}
+
+// clearPriceHints deletes the NodeOverlays of an earlier NodeOverlay output.
+func (r *LeftoverNodePoolReconciler) clearPriceHints(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API) {
+	if err := api.SyncNodeOverlays(ctx, r.Client, "leftover", cr.Status.NodePoolName, nil, cr); err != nil {
+		log.Error(err, "deleting NodeOverlays failed")
+		return
+	}
+	cr.Status.NodeOverlays = 0
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/karpenterx"
+)
+
+// applyPin replaces the selection with spec.pin while it has not expired and
+// reports how the pinned offering compares with the best candidate, if any.
+// It reports whether the pin applies; choices are returned unchanged if not.
+func (r *LeftoverNodePoolReconciler) applyPin(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, m *market, classes *karpenterx.NodeClasses, adopted *karpenterx.AdoptedNodePool, choices []choice) ([]choice, bool, error) {
+	pin := cr.Spec.Pin
+	if pin == nil {
+		cr.Status.Pin = nil
+		return choices, false, nil
+	}
+	st := &gpuv1alpha1.PinStatus{InstanceType: pin.InstanceType, Zone: pin.Zone, ExpiresAt: pin.ExpiresAt}
+	cr.Status.Pin = st
+	if len(choices) > 0 {
+		best := choices[0]
+		st.BestInstanceType, st.BestZone = best.quote.InstanceType, best.quote.Zone
+		st.BestPriceUSD, st.BestScore = fmt.Sprintf("%.4f", best.quote.PriceUSD), best.score
+	}
+	if pin.ExpiresAt != nil && !time.Now().Before(pin.ExpiresAt.Time) {
+		log.Info("Pin expired; selecting normally", "instanceType", pin.InstanceType, "zone", pin.Zone, "expiredAt", pin.ExpiresAt)
+		return choices, false, nil
+	}
+	st.Active = true
+
+	q, ok := m.quotes[[2]string{pin.InstanceType, pin.Zone}]
+	if !ok {
+		q = awsx.SpotQuote{InstanceType: pin.InstanceType, Zone: pin.Zone}
+	} else {
+		st.PriceUSD = fmt.Sprintf("%.4f", q.PriceUSD)
+	}
+	if perr := preflight(classes, adopted, m.meta[q.InstanceType], q); perr != nil {
+		return nil, false, withReason(perr.Reason, fmt.Errorf("pinned offering %s in %s: %s", q.InstanceType, q.Zone, perr.Message))
+	}
+	nodeClass, _ := classes.For(q.InstanceType)
+	c := choice{quote: q, nodeClass: nodeClass}
+	if m.scorer != nil {
+		c.score, _ = m.scorer.ScoreFor(ctx, q.InstanceType, q.Zone)
+		st.Score = c.score
+	}
+	if pin.CapacityType != "" {
+		cr.Spec.CapacityType = pin.CapacityType
+	}
+	cr.Status.PendingSelection = nil
+	log.Info("Applying pinned offering", "instanceType", q.InstanceType, "zone", q.Zone, "capacityType", cr.Spec.CapacityType,
+		"bestInstanceType", st.BestInstanceType, "bestZone", st.BestZone)
+	return []choice{c}, true, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+)
+
+func TestApplyPinInactive(t *testing.T) {
+	choices := []choice{testChoice("g6.xlarge", "us-east-1b", 0.8, 9)}
+	expired := metav1.NewTime(time.Now().Add(-time.Minute))
+
+	cases := []struct {
+		name       string
+		pin        *gpuv1alpha1.Pin
+		wantStatus bool
+	}{
+		{"no pin", nil, false},
+		{"expired", &gpuv1alpha1.Pin{InstanceType: "g5.xlarge", Zone: "us-east-1a", CapacityType: "on-demand", ExpiresAt: &expired}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := &gpuv1alpha1.LeftoverNodePool{Spec: gpuv1alpha1.LeftoverNodePoolSpec{CapacityType: "spot", Pin: tc.pin}}
+			cr.Status.Pin = &gpuv1alpha1.PinStatus{Active: true}
+			// The market is not consulted for an inactive pin.
+			got, pinned, err := (&LeftoverNodePoolReconciler{}).applyPin(t.Context(), logr.Discard(), cr, nil, nil, nil, choices)
+			if pinned || err != nil || !sameOfferings(got, choices) {
+				t.Fatalf("got %v, pinned %v, err %v; want the selection", describeOfferings(got), pinned, err)
+			}
+			if cr.Spec.CapacityType != "spot" {
+				t.Errorf("capacity type = %s, want spot", cr.Spec.CapacityType)
+			}
+			st := cr.Status.Pin
+			if !tc.wantStatus {
+				if st != nil {
+					t.Fatalf("pin status = %+v, want none", st)
+				}
+				return
+			}
+			if st == nil || st.Active || st.InstanceType != "g5.xlarge" || st.BestInstanceType != "g6.xlarge" || st.BestPriceUSD != "0.8000" || st.BestScore != 9 {
+				t.Fatalf("pin status = %+v", st)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/karpenterx"
+)
+
+// defaultRolloutMaxAge bounds how long Additive keeps a previous offering.
+const defaultRolloutMaxAge = 24 * time.Hour
+
+func rolloutType(cr *gpuv1alpha1.LeftoverNodePool) string {
+	if s := cr.Spec.RolloutStrategy; s != nil && s.Type != "" {
+		return s.Type
+	}
+	return gpuv1alpha1.RolloutInPlace
+}
+
+func rolloutMaxAge(cr *gpuv1alpha1.LeftoverNodePool) time.Duration {
+	if s := cr.Spec.RolloutStrategy; s != nil && s.MaxAge != "" {
+		if d, err := time.ParseDuration(s.MaxAge); err == nil {
+			return d
+		}
+	}
+	return defaultRolloutMaxAge
+}
+
+// planRollout adapts params to the rollout strategy before they are applied:
+// BlueGreen names a NodePool per selection, Additive adds the previous
+// offerings that still have nodes to the requirements.
+func (r *LeftoverNodePoolReconciler) planRollout(ctx context.Context, cr *gpuv1alpha1.LeftoverNodePool, params *karpenterx.NodePoolParams, best karpenterx.Offering) (*gpuv1alpha1.RolloutStatus, error) {
+	st := &gpuv1alpha1.RolloutStatus{Strategy: rolloutType(cr)}
+	switch st.Strategy {
+	case gpuv1alpha1.RolloutBlueGreen:
+		params.Name = selectionNodePoolName(cr, *params)
+	case gpuv1alpha1.RolloutAdditive:
+		nodes, err := karpenterx.PoolNodes(ctx, r.Client, params.Name)
+		if err != nil {
+			return nil, withReason("RolloutError", fmt.Errorf("listing nodes of NodePool %q: %w", params.Name, err))
+		}
+		now := time.Now().Truncate(time.Second)
+		retained := karpenterx.Retain(retainedFromStatus(cr), previousOfferings(cr, params.Name), best, nodes, now, rolloutMaxAge(cr))
+		for _, o := range retained {
+			params.AddOffering(o.Offering, o.Architecture)
+			if len(params.Attributes) > 0 {
+				params.AddAttributes(o.Attributes)
+			}
+			st.RetainedOfferings = append(st.RetainedOfferings, gpuv1alpha1.RetainedOffering{
+				InstanceType: o.InstanceType,
+				Zone:         o.Zone,
+				Since:        metav1.NewTime(o.Since),
+				Nodes:        o.Nodes,
+			})
+		}
+	}
+	return st, nil
+}
+
+// selectionNodePoolName is the NodePool of a selection under BlueGreen and of
+// a diversified offering: leftover-<name>-<hash of offering, capacity type and
+// node class>.
+func selectionNodePoolName(cr *gpuv1alpha1.LeftoverNodePool, p karpenterx.NodePoolParams) string {
+	h := fnv.New32a()
+	for _, s := range [][]string{p.InstanceTypes, p.Zones, {p.CapacityType, p.NodeClassName}} {
+		for _, v := range s {
+			_, _ = h.Write([]byte(v))
+			_, _ = h.Write([]byte{0})
+		}
+	}
+	return fmt.Sprintf("%s-%08x", ownedNodePoolName(cr), h.Sum32())
+}
+
+// retainedFromStatus returns the offerings Additive retained so far.
+func retainedFromStatus(cr *gpuv1alpha1.LeftoverNodePool) []karpenterx.Retained {
+	if cr.Status.Rollout == nil {
+		return nil
+	}
+	out := make([]karpenterx.Retained, 0, len(cr.Status.Rollout.RetainedOfferings))
+	for _, o := range cr.Status.Rollout.RetainedOfferings {
+		out = append(out, karpenterx.Retained{
+			Offering: karpenterx.Offering{InstanceType: o.InstanceType, Zone: o.Zone},
+			Since:    o.Since.Time,
+		})
+	}
+	return out
+}
+
+// previousOfferings returns the last applied selection if it went to nodePool.
+func previousOfferings(cr *gpuv1alpha1.LeftoverNodePool, nodePool string) []karpenterx.Offering {
+	if cr.Status.NodePoolName != nodePool {
+		return nil
+	}
+	types, zones := cr.Status.SelectedInstanceTypes, cr.Status.SelectedZones
+	out := make([]karpenterx.Offering, 0, len(types))
+	for i := range min(len(types), len(zones)) {
+		out = append(out, karpenterx.Offering{InstanceType: types[i], Zone: zones[i]})
+	}
+	return out
+}
+
+// ownedNodePools lists the NodePools controlled by cr. Failures are logged;
+// the rollout then skips releasing and draining for this reconcile.
+func (r *LeftoverNodePoolReconciler) ownedNodePools(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API) []karpenterx.OwnedNodePool {
+	pools, err := api.OwnedNodePools(ctx, r.Client, cr)
+	if err != nil {
+		log.Error(err, "listing owned NodePools failed")
+	}
+	return pools
+}
+
+// releaseNodePools lifts the drain of active NodePools, e.g. when a selection
+// returns to a NodePool it left before. It runs before the NodePools are
+// applied so the drain limit does not conflict with their own limits.
+func (r *LeftoverNodePoolReconciler) releaseNodePools(ctx context.Context, log logr.Logger, api *karpenterx.API, pools []karpenterx.OwnedNodePool, active map[string]bool) {
+	for _, np := range pools {
+		if !active[np.Name] || np.DrainingSince == nil {
+			continue
+		}
+		if err := api.UndrainNodePool(ctx, r.Client, np.Name); err != nil {
+			log.Error(err, "releasing drained NodePool failed", "name", np.Name)
+		}
+	}
+}
+
+// finishRollout drains the owned NodePools that are not active and deletes
+// them once their nodes are gone, then records the rollout in the status.
+func (r *LeftoverNodePoolReconciler) finishRollout(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API, pools []karpenterx.OwnedNodePool, active map[string]bool, st *gpuv1alpha1.RolloutStatus) {
+	for _, np := range pools {
+		if active[np.Name] {
+			continue
+		}
+		if d := r.drainNodePool(ctx, log, cr, api, np); d != nil {
+			st.DrainingNodePools = append(st.DrainingNodePools, *d)
+		}
+	}
+	st.Phase = gpuv1alpha1.RolloutPhaseStable
+	if len(st.RetainedOfferings)+len(st.DrainingNodePools) > 0 {
+		st.Phase = gpuv1alpha1.RolloutPhaseProgressing
+	}
+	cr.Status.Rollout = st
+}
+
+// drainNodePool drains np, or deletes it if it has no nodes left. It returns
+// the draining NodePool, or nil if it was deleted or could not be drained.
+func (r *LeftoverNodePoolReconciler) drainNodePool(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, api *karpenterx.API, np karpenterx.OwnedNodePool) *gpuv1alpha1.DrainingNodePool {
+	nodes, err := karpenterx.PoolNodes(ctx, r.Client, np.Name)
+	if err != nil {
+		log.Error(err, "listing nodes of previous NodePool failed", "name", np.Name)
+		return nil
+	}
+	if len(nodes) == 0 {
+		if err := api.DeleteOwnedNodePool(ctx, r.Client, np.Name, cr); err != nil {
+			log.Error(err, "deleting drained NodePool failed", "name", np.Name)
+		} else {
+			log.Info("Deleted drained NodePool", "name", np.Name)
+		}
+		return nil
+	}
+	since := time.Now().Truncate(time.Second)
+	if np.DrainingSince != nil {
+		since = *np.DrainingSince
+	} else if err := api.DrainNodePool(ctx, r.Client, np.Name, since); err != nil {
+		log.Error(err, "draining previous NodePool failed", "name", np.Name)
+		return nil
+	} else {
+		log.Info("Draining previous NodePool", "name", np.Name, "nodes", len(nodes))
+	}
+	return &gpuv1alpha1.DrainingNodePool{
+		Name:  np.Name,
+		Since: metav1.NewTime(since),
+		Nodes: int32(len(nodes)),
+	}
+}
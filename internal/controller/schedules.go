@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/schedule"
+)
+
+// scheduleWindows parses the windows of spec.schedules by name; invalid
+// windows, which the webhook rejects, are logged and skipped.
+func scheduleWindows(log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool) map[string]*schedule.Window {
+	out := make(map[string]*schedule.Window, len(cr.Spec.Schedules))
+	for _, s := range cr.Spec.Schedules {
+		w, err := schedule.NewWindow(s.Schedule, s.Duration, s.TimeZone)
+		if err != nil {
+			log.Error(err, "Ignoring invalid schedule", "schedule", s.Name)
+			continue
+		}
+		out[s.Name] = w
+	}
+	return out
+}
+
+// applySchedules overrides capacityType, minSpotScore and strategy of the
+// in-memory spec with the schedules open at now, in spec order, and records
+// them in status.activeSchedules. The stored object is not changed; only the
+// status is written back.
+func applySchedules(log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, now time.Time) {
+	windows := scheduleWindows(log, cr)
+	var active []string
+	for i := range cr.Spec.Schedules {
+		s := &cr.Spec.Schedules[i]
+		if w := windows[s.Name]; w == nil || !w.Open(now) {
+			continue
+		}
+		active = append(active, s.Name)
+		if s.CapacityType != "" {
+			cr.Spec.CapacityType = s.CapacityType
+		}
+		if s.MinSpotScore != nil {
+			cr.Spec.MinSpotScore = *s.MinSpotScore
+		}
+		if s.Strategy != nil {
+			cr.Spec.Strategy = s.Strategy
+		}
+	}
+	if len(active) > 0 {
+		log.Info("Schedules active", "schedules", active, "capacityType", cr.Spec.CapacityType, "minSpotScore", cr.Spec.MinSpotScore)
+	}
+	cr.Status.ActiveSchedules = active
+}
+
+// scheduledLimits returns the NodePool limits of the last active schedule
+// that sets them, or nil.
+func scheduledLimits(cr *gpuv1alpha1.LeftoverNodePool) corev1.ResourceList {
+	active := make(map[string]bool, len(cr.Status.ActiveSchedules))
+	for _, name := range cr.Status.ActiveSchedules {
+		active[name] = true
+	}
+	var limits corev1.ResourceList
+	for _, s := range cr.Spec.Schedules {
+		if active[s.Name] && s.Limits != nil {
+			limits = s.Limits
+		}
+	}
+	return limits
+}
+
+// nextScheduleChange returns when the next schedule window opens or closes,
+// or the zero time without schedules.
+func nextScheduleChange(log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, now time.Time) time.Time {
+	var next time.Time
+	for _, w := range scheduleWindows(log, cr) {
+		if t := w.NextChange(now); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
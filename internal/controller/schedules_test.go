@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+)
+
+func TestApplySchedules(t *testing.T) {
+	always := gpuv1alpha1.TimeWindow{Schedule: "* * * * *", Duration: "1h"}
+	never := gpuv1alpha1.TimeWindow{Schedule: "0 0 29 2 *", Duration: "1m"}
+	score := func(v int32) *int32 { return &v }
+	expected := &gpuv1alpha1.SelectionStrategy{Type: gpuv1alpha1.StrategyExpectedPrice}
+	limits := corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("8")}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name         string
+		schedules    []gpuv1alpha1.CapacitySchedule
+		wantActive   []string
+		wantCapacity string
+		wantScore    int32
+		wantStrategy *gpuv1alpha1.SelectionStrategy
+		wantLimits   corev1.ResourceList
+	}{
+		{"none", nil, nil, "spot", 5, nil, nil},
+		{"closed", []gpuv1alpha1.CapacitySchedule{{Name: "night", TimeWindow: never, CapacityType: "on-demand"}},
+			nil, "spot", 5, nil, nil},
+		{"open", []gpuv1alpha1.CapacitySchedule{{Name: "day", TimeWindow: always, CapacityType: "on-demand", MinSpotScore: score(8), Strategy: expected, Limits: limits}},
+			[]string{"day"}, "on-demand", 8, expected, limits},
+		{"later wins", []gpuv1alpha1.CapacitySchedule{
+			{Name: "a", TimeWindow: always, CapacityType: "on-demand", MinSpotScore: score(8)},
+			{Name: "b", TimeWindow: always, MinSpotScore: score(2)},
+			{Name: "c", TimeWindow: never, MinSpotScore: score(9)},
+		}, []string{"a", "b"}, "on-demand", 2, nil, nil},
+		{"invalid skipped", []gpuv1alpha1.CapacitySchedule{{Name: "bad", TimeWindow: gpuv1alpha1.TimeWindow{Schedule: "nope", Duration: "1h"}, CapacityType: "on-demand"}},
+			nil, "spot", 5, nil, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := &gpuv1alpha1.LeftoverNodePool{Spec: gpuv1alpha1.LeftoverNodePoolSpec{
+				CapacityType: "spot", MinSpotScore: 5, Schedules: tc.schedules,
+			}}
+			applySchedules(logr.Discard(), cr, now)
+			if !reflect.DeepEqual(cr.Status.ActiveSchedules, tc.wantActive) {
+				t.Errorf("active = %v, want %v", cr.Status.ActiveSchedules, tc.wantActive)
+			}
+			if cr.Spec.CapacityType != tc.wantCapacity || cr.Spec.MinSpotScore != tc.wantScore || cr.Spec.Strategy != tc.wantStrategy {
+				t.Errorf("spec = %s/%d/%v, want %s/%d/%v", cr.Spec.CapacityType, cr.Spec.MinSpotScore, cr.Spec.Strategy,
+					tc.wantCapacity, tc.wantScore, tc.wantStrategy)
+			}
+			if got := scheduledLimits(cr); !reflect.DeepEqual(got, tc.wantLimits) {
+				t.Errorf("limits = %v, want %v", got, tc.wantLimits)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/karpenterx"
+)
+
+const (
+	labelCapacityType = "karpenter.sh/capacity-type"
+	resourceGPU       = corev1.ResourceName("nvidia.com/gpu")
+	// maxShadowInterval bounds the time one comparison accounts for, so an
+	// operator outage is not extrapolated.
+	maxShadowInterval = time.Hour
+)
+
+// isShadow reports whether cr only compares its selection instead of applying it.
+func isShadow(cr *gpuv1alpha1.LeftoverNodePool) bool {
+	return cr.Spec.Mode == gpuv1alpha1.ModeShadow
+}
+
+// recordShadow compares the selection with the nodes of the spec.nodePoolRef
+// NodePool and accumulates the estimated savings in status.shadow. Nothing
+// is written to Karpenter objects.
+func (r *LeftoverNodePoolReconciler) recordShadow(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, m *market, choices []choice) error {
+	nodePool := cr.Spec.NodePoolRef.Name
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes, client.MatchingLabels{karpenterx.LabelNodePool: nodePool}); err != nil {
+		return withReason("ShadowError", fmt.Errorf("listing nodes of NodePool %q: %w", nodePool, err))
+	}
+
+	now := time.Now()
+	prev := cr.Status.Shadow
+	st := &gpuv1alpha1.ShadowStatus{
+		NodePoolName:       nodePool,
+		Since:              metav1.Time{Time: now},
+		LastEvaluationTime: metav1.Time{Time: now},
+	}
+	for _, c := range choices {
+		st.InstanceTypes = append(st.InstanceTypes, c.quote.InstanceType)
+		st.Zones = append(st.Zones, c.quote.Zone)
+	}
+	best := choices[0]
+	st.Score = best.score
+
+	prices := r.shadowPrices(ctx, log, m, nodes.Items)
+	actualHourly, offerings := 0.0, map[[3]string]*gpuv1alpha1.ShadowOffering{}
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		key := [3]string{n.Labels[corev1.LabelInstanceTypeStable], n.Labels[corev1.LabelTopologyZone], n.Labels[labelCapacityType]}
+		o := offerings[key]
+		if o == nil {
+			o = &gpuv1alpha1.ShadowOffering{InstanceType: key[0], Zone: key[1], CapacityType: key[2]}
+			if p, ok := prices[key]; ok {
+				o.PriceUSD = fmt.Sprintf("%.4f", p)
+			}
+			offerings[key] = o
+		}
+		o.Nodes++
+		p, ok := prices[key]
+		if !ok {
+			st.UnpricedNodes++
+			continue
+		}
+		actualHourly += p
+		st.ActualGPUs += nodeGPUs(n, m)
+	}
+	for _, o := range offerings {
+		st.ActualOfferings = append(st.ActualOfferings, *o)
+	}
+	sort.Slice(st.ActualOfferings, func(i, j int) bool { return st.ActualOfferings[i].Nodes > st.ActualOfferings[j].Nodes })
+
+	bestPrice := best.quote.PriceUSD
+	if cr.Spec.CapacityType == "on-demand" {
+		if od, err := m.cli.OnDemandPrices(ctx, m.product); err == nil && od[best.quote.InstanceType] > 0 {
+			bestPrice = od[best.quote.InstanceType]
+		}
+	}
+	st.PriceUSD = fmt.Sprintf("%.4f", bestPrice)
+	if gpus := int64(m.meta[best.quote.InstanceType].GPUCount); gpus > 0 && st.ActualGPUs > 0 {
+		st.ShadowNodes = int32((st.ActualGPUs + gpus - 1) / gpus)
+	}
+	shadowHourly := float64(st.ShadowNodes) * bestPrice
+	st.ActualHourlyUSD = fmt.Sprintf("%.4f", actualHourly)
+	st.ShadowHourlyUSD = fmt.Sprintf("%.4f", shadowHourly)
+
+	accumulateShadow(st, prev, now)
+	cr.Status.Shadow = st
+	// Change windows do not gate shadow selections.
+	cr.Status.PendingSelection = nil
+
+	log.Info("Shadow selection recorded; NodePool not modified", "nodePool", nodePool,
+		"instanceType", best.quote.InstanceType, "zone", best.quote.Zone,
+		"actualHourlyUSD", st.ActualHourlyUSD, "shadowHourlyUSD", st.ShadowHourlyUSD, "estimatedSavingsUSD", st.EstimatedSavingsUSD)
+	return nil
+}
+
+// accumulateShadow carries the savings of prev over to st when both compare
+// the same NodePool, adding the interval since prev at the rates observed
+// then; otherwise the estimate starts over at now.
+func accumulateShadow(st, prev *gpuv1alpha1.ShadowStatus, now time.Time) {
+	savings := 0.0
+	if prev != nil && prev.NodePoolName == st.NodePoolName {
+		st.Since = prev.Since
+		savings = parseUSD(prev.EstimatedSavingsUSD)
+		elapsed := min(now.Sub(prev.LastEvaluationTime.Time), maxShadowInterval)
+		savings += (parseUSD(prev.ActualHourlyUSD) - parseUSD(prev.ShadowHourlyUSD)) * elapsed.Hours()
+	}
+	st.EstimatedSavingsUSD = fmt.Sprintf("%.4f", savings)
+}
+
+// shadowPrices returns the hourly price per (instance type, zone, capacity
+// type) of nodes: the latest Spot price, or the on-demand price. Types the
+// market did not quote are looked up; failed lookups leave nodes unpriced.
+func (r *LeftoverNodePoolReconciler) shadowPrices(ctx context.Context, log logr.Logger, m *market, nodes []corev1.Node) map[[3]string]float64 {
+	var missing []string
+	seen := map[string]bool{}
+	for _, n := range nodes {
+		t, z := n.Labels[corev1.LabelInstanceTypeStable], n.Labels[corev1.LabelTopologyZone]
+		if _, ok := m.quotes[[2]string{t, z}]; !ok && t != "" && !seen[t] && n.Labels[labelCapacityType] != "on-demand" {
+			seen[t] = true
+			missing = append(missing, t)
+		}
+	}
+	quotes := m.quotes
+	if len(missing) > 0 {
+		extra, err := m.cli.LatestSpotPrices(ctx, m.product, missing, 10*time.Minute)
+		if err != nil {
+			log.Error(err, "Spot price lookup for NodePool nodes failed")
+		}
+		quotes = make(map[[2]string]awsx.SpotQuote, len(m.quotes)+len(extra))
+		for k, q := range m.quotes {
+			quotes[k] = q
+		}
+		for k, q := range extra {
+			quotes[k] = q
+		}
+	}
+	onDemand, err := m.cli.OnDemandPrices(ctx, m.product)
+	if err != nil {
+		log.Error(err, "On-demand price lookup failed")
+	}
+
+	out := map[[3]string]float64{}
+	for _, n := range nodes {
+		t, z, ct := n.Labels[corev1.LabelInstanceTypeStable], n.Labels[corev1.LabelTopologyZone], n.Labels[labelCapacityType]
+		if ct == "on-demand" {
+			if p, ok := onDemand[t]; ok {
+				out[[3]string{t, z, ct}] = p
+			}
+		} else if q, ok := quotes[[2]string{t, z}]; ok {
+			out[[3]string{t, z, ct}] = q.PriceUSD
+		}
+	}
+	return out
+}
+
+// nodeGPUs returns the GPUs of a node: its reported capacity, else those of
+// its instance type.
+func nodeGPUs(n *corev1.Node, m *market) int64 {
+	if q, ok := n.Status.Capacity[resourceGPU]; ok && !q.IsZero() {
+		return q.Value()
+	}
+	return int64(m.meta[n.Labels[corev1.LabelInstanceTypeStable]].GPUCount)
+}
+
+func parseUSD(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// readyMessage is the Ready condition message of a successful reconcile.
+func readyMessage(cr *gpuv1alpha1.LeftoverNodePool) string {
+	if isShadow(cr) {
+		return "Shadow selection recorded; NodePool not modified"
+	}
+	return "NodePool updated"
+}
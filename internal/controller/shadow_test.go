@@ -0,0 +1,62 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+)
+
+func TestAccumulateShadow(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	since := metav1.NewTime(now.Add(-24 * time.Hour))
+	prev := func(elapsed time.Duration) *gpuv1alpha1.ShadowStatus {
+		return &gpuv1alpha1.ShadowStatus{
+			NodePoolName:        "gpu",
+			Since:               since,
+			LastEvaluationTime:  metav1.NewTime(now.Add(-elapsed)),
+			ActualHourlyUSD:     "10.0000",
+			ShadowHourlyUSD:     "6.0000",
+			EstimatedSavingsUSD: "100.0000",
+		}
+	}
+	cases := []struct {
+		name        string
+		prev        *gpuv1alpha1.ShadowStatus
+		wantSavings string
+		wantSince   time.Time
+	}{
+		{"first evaluation", nil, "0.0000", now},
+		{"half an hour", prev(30 * time.Minute), "102.0000", since.Time},
+		// An outage accounts for at most maxShadowInterval.
+		{"capped interval", prev(5 * time.Hour), "104.0000", since.Time},
+		{"other NodePool", func() *gpuv1alpha1.ShadowStatus { p := prev(30 * time.Minute); p.NodePoolName = "old"; return p }(), "0.0000", now},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := &gpuv1alpha1.ShadowStatus{NodePoolName: "gpu", Since: metav1.NewTime(now)}
+			accumulateShadow(st, tc.prev, now)
+			if st.EstimatedSavingsUSD != tc.wantSavings || !st.Since.Time.Equal(tc.wantSince) {
+				t.Fatalf("savings %s since %v, want %s since %v", st.EstimatedSavingsUSD, st.Since, tc.wantSavings, tc.wantSince)
+			}
+		})
+	}
+}
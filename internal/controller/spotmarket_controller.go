@@ -0,0 +1,261 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/advisor"
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/history"
+)
+
+// SpotMarketReconciler publishes per-region market snapshots into SpotMarket resources.
+type SpotMarketReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	AWSFactory *awsx.Factory
+	// History records observed prices; nil disables price statistics.
+	History *history.Store
+	// Interruptions provides interruption-frequency buckets per instance type.
+	Interruptions *advisor.Provider
+}
+
+// +kubebuilder:rbac:groups=gpu.devplatforms.io,resources=spotmarkets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gpu.devplatforms.io,resources=spotmarkets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gpu.devplatforms.io,resources=spotmarkets/finalizers,verbs=update
+
+func (r *SpotMarketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx).WithValues("spotmarket", req.Name)
+
+	var sm gpuv1alpha1.SpotMarket
+	if err := r.Get(ctx, req.NamespacedName, &sm); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	interval := time.Duration(sm.Spec.RefreshMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	// Spec edits bump the generation; otherwise only refresh once the interval elapsed.
+	if due := sm.Status.NextRefreshTime.Time; !due.IsZero() && time.Now().Before(due) && !specChanged(&sm) {
+		return ctrl.Result{RequeueAfter: time.Until(due)}, nil
+	}
+
+	if err := r.refresh(ctx, log, &sm, interval); err != nil {
+		log.Error(err, "refresh failed")
+		return ctrl.Result{RequeueAfter: time.Minute}, err
+	}
+	log.Info("Requeue scheduled", "after", interval.String())
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// specChanged reports whether the Fresh condition was computed for an older generation.
+func specChanged(sm *gpuv1alpha1.SpotMarket) bool {
+	c := meta.FindStatusCondition(sm.Status.Conditions, gpuv1alpha1.ConditionFresh)
+	return c == nil || c.ObservedGeneration != sm.GetGeneration()
+}
+
+func (r *SpotMarketReconciler) refresh(ctx context.Context, log logr.Logger, sm *gpuv1alpha1.SpotMarket, interval time.Duration) error {
+	origStatus := sm.Status.DeepCopy()
+	now := time.Now()
+
+	fail := func(reason string, err error) error {
+		log.Error(err, "market snapshot failed", "reason", reason)
+		fresh := metav1.ConditionFalse
+		if !sm.Status.SnapshotTime.IsZero() && now.Sub(sm.Status.SnapshotTime.Time) < 2*interval {
+			fresh = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&sm.Status.Conditions, metav1.Condition{
+			Type:               gpuv1alpha1.ConditionFresh,
+			Status:             fresh,
+			Reason:             reason,
+			Message:            err.Error(),
+			ObservedGeneration: sm.GetGeneration(),
+		})
+		return r.updateStatusIfChanged(ctx, log, sm, origStatus)
+	}
+
+	awsCli, err := r.AWSFactory.ForRegion(ctx, sm.Spec.Region)
+	if err != nil {
+		return fail("AWSClientError", err)
+	}
+	types, metaByType, err := awsCli.ListGPUInstanceTypes(ctx, sm.Spec.Families, 1)
+	if err != nil {
+		return fail("ListTypesError", err)
+	}
+	quotes, err := awsCli.LatestSpotPrices(ctx, sm.Spec.ProductDescription, types, 10*time.Minute)
+	if err != nil {
+		return fail("SpotPriceError", err)
+	}
+	if r.History != nil {
+		if err := r.History.Observe(ctx, awsCli, sm.Spec.Region, sm.Spec.ProductDescription, quotes); err != nil {
+			log.Error(err, "recording price history failed")
+		}
+	}
+	// Snapshots score within their own budget so they cannot use up the one
+	// of LeftoverNodePools; both share the cached scores.
+	scorer, err := awsx.NewQuoteScorer(ctx, awsCli.ForMarket(), awsx.TypesByPrice(quotes), sm.Spec.TargetCount)
+	if err != nil {
+		return fail("ScorerError", err)
+	}
+	azIDs, err := awsCli.AZNameToID(ctx)
+	if err != nil {
+		return fail("ZoneError", err)
+	}
+	onDemand, err := awsCli.OnDemandPrices(ctx, sm.Spec.ProductDescription)
+	if err != nil {
+		// On-demand prices are informational; publish the snapshot without them.
+		log.Error(err, "on-demand price lookup failed")
+		onDemand = nil
+	}
+
+	sorted := make([]awsx.SpotQuote, 0, len(quotes))
+	for _, q := range quotes {
+		sorted = append(sorted, q)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].PriceUSD != sorted[j].PriceUSD {
+			return sorted[i].PriceUSD < sorted[j].PriceUSD
+		}
+		if sorted[i].InstanceType != sorted[j].InstanceType {
+			return sorted[i].InstanceType < sorted[j].InstanceType
+		}
+		return sorted[i].Zone < sorted[j].Zone
+	})
+
+	offerings := make([]gpuv1alpha1.SpotOffering, 0, len(sorted))
+	var oldest time.Time
+	for _, q := range sorted {
+		score, _ := scorer.ScoreFor(ctx, q.InstanceType, q.Zone)
+		m := metaByType[q.InstanceType]
+		o := gpuv1alpha1.SpotOffering{
+			InstanceType:    q.InstanceType,
+			Zone:            q.Zone,
+			ZoneID:          azIDs[q.Zone],
+			PriceUSD:        fmt.Sprintf("%.4f", q.PriceUSD),
+			QuoteTime:       metav1.NewTime(q.Timestamp),
+			QuoteAgeSeconds: int64(now.Sub(q.Timestamp).Seconds()),
+			Score:           score,
+			VCPUs:           m.VCPUs,
+			MemoryMiB:       m.MemoryMiB,
+			GPUCount:        m.GPUCount,
+			GPUMemoryMiB:    m.GPUMemMiB,
+			GPUName:         m.GPUName,
+			GPUManufacturer: m.GPUManufacturer,
+		}
+		if od, ok := onDemand[q.InstanceType]; ok {
+			o.OnDemandPriceUSD = fmt.Sprintf("%.4f", od)
+		}
+		if rate, known := r.Interruptions.Rate(ctx, sm.Spec.Region, advisor.OSForProduct(sm.Spec.ProductDescription), q.InstanceType); known {
+			o.InterruptionRate = rate.Label
+		}
+		if r.History != nil {
+			if st, ok := r.History.Stats(sm.Spec.Region, sm.Spec.ProductDescription, q.InstanceType, q.Zone, history.DefaultForecastHours); ok {
+				o.Volatility = fmt.Sprintf("%.4f", st.Volatility)
+				o.TrendPerDay = fmt.Sprintf("%.4f", st.TrendPerDay)
+				o.ForecastUSD = fmt.Sprintf("%.4f", st.ForecastUSD)
+			}
+		}
+		offerings = append(offerings, o)
+		if oldest.IsZero() || q.Timestamp.Before(oldest) {
+			oldest = q.Timestamp
+		}
+	}
+	stale, _ := scorer.Degraded()
+
+	sm.Status.Offerings = offerings
+	sm.Status.SnapshotTime = metav1.NewTime(now)
+	sm.Status.NextRefreshTime = metav1.NewTime(now.Add(interval))
+	sm.Status.StaleScoreTypes = stale
+	if !oldest.IsZero() {
+		sm.Status.OldestQuoteTime = metav1.NewTime(oldest)
+	}
+	meta.SetStatusCondition(&sm.Status.Conditions, metav1.Condition{
+		Type:               gpuv1alpha1.ConditionFresh,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Refreshed",
+		Message:            fmt.Sprintf("%d offerings across %d instance types", len(offerings), len(types)),
+		ObservedGeneration: sm.GetGeneration(),
+	})
+	log.Info("Published market snapshot", "region", sm.Spec.Region, "offerings", len(offerings))
+	return r.updateStatusIfChanged(ctx, log, sm, origStatus)
+}
+
+func (r *SpotMarketReconciler) updateStatusIfChanged(ctx context.Context, log logr.Logger, sm *gpuv1alpha1.SpotMarket, orig *gpuv1alpha1.SpotMarketStatus) error {
+	if reflect.DeepEqual(orig, &sm.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, sm); err != nil {
+		log.Error(err, "status update failed")
+		return err
+	}
+	return nil
+}
+
+// ensureSpotMarket creates the SpotMarket for region and product if it does not
+// exist yet and returns its name: the region, suffixed for non-Linux products.
+// SpotMarkets are shared by every LeftoverNodePool in the region, so they are
+// not owned by any of them.
+func ensureSpotMarket(ctx context.Context, c client.Client, region, product string) (string, error) {
+	name := region
+	if slug := awsx.ProductSlug(product); slug != "" {
+		name = region + "-" + slug
+	}
+	var existing gpuv1alpha1.SpotMarket
+	err := c.Get(ctx, client.ObjectKey{Name: name}, &existing)
+	if err == nil {
+		return name, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	sm := &gpuv1alpha1.SpotMarket{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"managed-by": "leftover"},
+		},
+		Spec: gpuv1alpha1.SpotMarketSpec{Region: region, ProductDescription: product},
+	}
+	if err := c.Create(ctx, sm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", err
+	}
+	return name, nil
+}
+
+// SetupWithManager wires the controller into the manager.
+func (r *SpotMarketReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gpuv1alpha1.SpotMarket{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
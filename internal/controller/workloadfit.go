@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/go-logr/logr"
+
+	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/demand"
+)
+
+// fitPlan is the packing of the workload onto each candidate instance type.
+type fitPlan struct {
+	pods     int
+	gpus     int64
+	packings map[string]demand.Packing
+}
+
+// workloadFit packs the workload onto every candidate instance type, or
+// returns nil unless strategy WorkloadFit is selected.
+func (r *LeftoverNodePoolReconciler) workloadFit(ctx context.Context, log logr.Logger, cr *gpuv1alpha1.LeftoverNodePool, m *market) (*fitPlan, error) {
+	if s := cr.Spec.Strategy; s == nil || s.Type != gpuv1alpha1.StrategyWorkloadFit {
+		cr.Status.WorkloadFit = nil
+		return nil, nil
+	}
+	nodePools := []string{}
+	if cr.Status.NodePoolName != "" {
+		nodePools = append(nodePools, cr.Status.NodePoolName)
+	}
+	for _, d := range cr.Status.DiversifiedNodePools {
+		nodePools = append(nodePools, d.Name)
+	}
+	w := &demand.Workload{Reader: r.Client, Labels: cr.Spec.Labels, Taints: cr.Spec.Taints, NodePools: nodePools}
+	requests, err := w.Requests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing workload pods: %w", err)
+	}
+
+	plan := &fitPlan{pods: len(requests), packings: make(map[string]demand.Packing, len(m.meta))}
+	for _, req := range requests {
+		plan.gpus += req.GPUs
+	}
+	for t, meta := range m.meta {
+		plan.packings[t] = demand.Pack(requests, demand.ShapeFor(meta.GPUCount, meta.VCPUs, meta.MemoryMiB))
+	}
+	log.Info("Packed workload onto candidate instance types", "pods", plan.pods, "gpus", plan.gpus, "unfitTypes", plan.unfit())
+	return plan, nil
+}
+
+// fits reports whether every pod fits a node of the instance type.
+func (p *fitPlan) fits(instanceType string) bool {
+	pk, ok := p.packings[instanceType]
+	return ok && pk.Unplaced == 0
+}
+
+// unfit counts the instance types a pod exceeds.
+func (p *fitPlan) unfit() int {
+	n := 0
+	for _, pk := range p.packings {
+		if pk.Unplaced > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// nodes returns the nodes the workload needs on the instance type; without
+// pods the pool still needs one.
+func (p *fitPlan) nodes(instanceType string) int {
+	return max(p.packings[instanceType].Nodes, 1)
+}
+
+// fleetCost ranks offerings by the price of all nodes the workload needs.
+func (p *fitPlan) fleetCost(price func(awsx.SpotQuote) float64) func(awsx.SpotQuote) float64 {
+	return func(q awsx.SpotQuote) float64 {
+		if !p.fits(q.InstanceType) {
+			return math.Inf(1)
+		}
+		return float64(p.nodes(q.InstanceType)) * price(q)
+	}
+}
+
+// record explains the packing onto the best choice in the status,
+// next to the cheapest fleet of another instance type.
+func (p *fitPlan) record(cr *gpuv1alpha1.LeftoverNodePool, m *market, best awsx.SpotQuote, cost func(awsx.SpotQuote) float64) {
+	pk := p.packings[best.InstanceType]
+	st := &gpuv1alpha1.WorkloadFitStatus{
+		Pods:               int32(p.pods),
+		GPUs:               p.gpus,
+		Nodes:              int32(p.nodes(best.InstanceType)),
+		FleetPriceUSD:      fmt.Sprintf("%.4f", cost(best)),
+		UnfitInstanceTypes: int32(p.unfit()),
+	}
+	if capacity := int64(st.Nodes) * int64(m.meta[best.InstanceType].GPUCount); capacity > 0 {
+		st.GPUUtilizationPercent = int32(pk.GPUs * 100 / capacity)
+	}
+
+	var runnerUp awsx.SpotQuote
+	runnerUpCost := math.Inf(1)
+	for _, q := range m.quotes {
+		if q.InstanceType == best.InstanceType {
+			continue
+		}
+		if c := cost(q); c < runnerUpCost {
+			runnerUp, runnerUpCost = q, c
+		}
+	}
+	if !math.IsInf(runnerUpCost, 1) {
+		st.RunnerUp = &gpuv1alpha1.WorkloadFitCandidate{
+			InstanceType:  runnerUp.InstanceType,
+			Nodes:         int32(p.nodes(runnerUp.InstanceType)),
+			FleetPriceUSD: fmt.Sprintf("%.4f", runnerUpCost),
+		}
+	}
+	cr.Status.WorkloadFit = st
+}
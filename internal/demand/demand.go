@@ -0,0 +1,60 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package demand estimates how many nodes a LeftoverNodePool's workloads
+// need. The estimate replaces the static targetCount as the target capacity
+// of Spot placement score requests, so scores describe the capacity that is
+// actually about to be requested. It also packs the workload's pod requests
+// onto candidate instance shapes for the WorkloadFit strategy.
+package demand
+
+import (
+	"context"
+	"math"
+)
+
+// Source estimates the number of nodes needed.
+type Source interface {
+	// Name identifies the source in status and logs.
+	Name() string
+	// Nodes returns the estimated number of nodes needed.
+	Nodes(ctx context.Context) (int32, error)
+}
+
+// TargetCount clamps an estimate to [floor, max]; max <= 0 means unbounded.
+func TargetCount(nodes, floor, max int32) int32 {
+	if nodes < floor {
+		nodes = floor
+	}
+	if max > 0 && nodes > max {
+		nodes = max
+	}
+	if nodes < 1 {
+		nodes = 1
+	}
+	return nodes
+}
+
+// nodesFor converts a fractional node count into whole nodes.
+func nodesFor(v float64) int32 {
+	if math.IsNaN(v) || v <= 0 {
+		return 0
+	}
+	if v >= math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(math.Ceil(v))
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package demand
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func gpuPod(gpus string, selector map[string]string, tolerations ...corev1.Toleration) *corev1.Pod {
+	return &corev1.Pod{
+		Spec: corev1.PodSpec{
+			NodeSelector: selector,
+			Tolerations:  tolerations,
+			Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse(gpus)},
+			}}},
+		},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+		}}},
+	}
+}
+
+// affinityPod requires node affinity with one term per requirement.
+func affinityPod(toleration corev1.Toleration, terms ...corev1.NodeSelectorRequirement) *corev1.Pod {
+	pod := gpuPod("1", nil, toleration)
+	selector := &corev1.NodeSelector{}
+	for _, req := range terms {
+		selector.NodeSelectorTerms = append(selector.NodeSelectorTerms, corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{req},
+		})
+	}
+	pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: selector}}
+	return pod
+}
+
+func TestSchedulableOn(t *testing.T) {
+	labels := map[string]string{"team": "ml"}
+	taints := parseTaints([]string{"gpu=true:NoSchedule", "spot:PreferNoSchedule", "malformed"})
+	if len(taints) != 2 {
+		t.Fatalf("parsed %d taints, want 2", len(taints))
+	}
+	tolerate := corev1.Toleration{Key: "gpu", Operator: corev1.TolerationOpEqual, Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	cases := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{"tolerates", gpuPod("1", nil, tolerate), true},
+		{"untolerated taint", gpuPod("1", nil), false},
+		{"selects pool label", gpuPod("1", map[string]string{"team": "ml"}, tolerate), true},
+		{"selects other value", gpuPod("1", map[string]string{"team": "web"}, tolerate), false},
+		{"selects unknown label", gpuPod("1", map[string]string{"disk": "ssd"}, tolerate), false},
+		{"selects well-known label", gpuPod("1", map[string]string{"karpenter.sh/capacity-type": "spot"}, tolerate), true},
+		{"affinity in pool value", affinityPod(tolerate, corev1.NodeSelectorRequirement{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"web", "ml"}}), true},
+		{"affinity not in pool value", affinityPod(tolerate, corev1.NodeSelectorRequirement{Key: "team", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"ml"}}), false},
+		{"affinity on unknown label", affinityPod(tolerate, corev1.NodeSelectorRequirement{Key: "disk", Operator: corev1.NodeSelectorOpExists}), false},
+		{"affinity excludes unknown label", affinityPod(tolerate, corev1.NodeSelectorRequirement{Key: "disk", Operator: corev1.NodeSelectorOpDoesNotExist}), true},
+		{"affinity on well-known label", affinityPod(tolerate, corev1.NodeSelectorRequirement{Key: "node.kubernetes.io/instance-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"g5.xlarge"}}), true},
+		{"affinity any term", affinityPod(tolerate,
+			corev1.NodeSelectorRequirement{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"web"}},
+			corev1.NodeSelectorRequirement{Key: "team", Operator: corev1.NodeSelectorOpExists}), true},
+	}
+	for _, tc := range cases {
+		if got := schedulableOn(tc.pod, labels, taints); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPodRequest(t *testing.T) {
+	pod := gpuPod("2", nil)
+	pod.Spec.Containers = append(pod.Spec.Containers, pod.Spec.Containers[0])
+	pod.Spec.InitContainers = []corev1.Container{{Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("3")},
+	}}}
+	if got := podRequest(pod).GPUs; got != 4 {
+		t.Fatalf("got %d GPUs, want 4", got)
+	}
+	if !unschedulable(pod) {
+		t.Fatal("pod should be unschedulable")
+	}
+	pod.Spec.NodeName = "node-a"
+	if unschedulable(pod) {
+		t.Fatal("bound pod counted as unschedulable")
+	}
+}
+
+func TestTargetCount(t *testing.T) {
+	cases := []struct{ nodes, floor, max, want int32 }{
+		{0, 2, 0, 2},
+		{40, 2, 0, 40},
+		{40, 2, 25, 25},
+		{0, 0, 0, 1},
+	}
+	for _, tc := range cases {
+		if got := TargetCount(tc.nodes, tc.floor, tc.max); got != tc.want {
+			t.Errorf("TargetCount(%d, %d, %d) = %d, want %d", tc.nodes, tc.floor, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestPrometheusNodes(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"12.2"]}]}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cli, err := NewPrometheusClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := (&Prometheus{Client: cli, Query: "sum(gpu_jobs_queued)"}).Nodes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 13 {
+		t.Fatalf("got %d nodes, want 13", n)
+	}
+
+	if _, err := (&Prometheus{Query: "up"}).Nodes(context.Background()); err != ErrNoPrometheus {
+		t.Fatalf("got %v, want ErrNoPrometheus", err)
+	}
+}
+
+func TestPack(t *testing.T) {
+	oneGPU := Request{GPUs: 1, MilliCPU: 4000, MemoryB: 16 << 30}
+	requests := []Request{oneGPU, oneGPU, oneGPU, oneGPU, oneGPU, oneGPU, oneGPU, oneGPU}
+
+	// g5.xlarge: 1 GPU, 4 vCPUs, 16 GiB; the CPU request does not fit after reservations.
+	if p := Pack(requests, ShapeFor(1, 4, 16384)); p.Unplaced != 8 || p.Nodes != 0 {
+		t.Fatalf("g5.xlarge: got %+v", p)
+	}
+	// g5.2xlarge: 1 GPU, 8 vCPUs, 32 GiB.
+	if p := Pack(requests, ShapeFor(1, 8, 32768)); p.Nodes != 8 || p.Unplaced != 0 {
+		t.Fatalf("g5.2xlarge: got %+v", p)
+	}
+	// g5.48xlarge: 8 GPUs, 192 vCPUs, 768 GiB.
+	if p := Pack(requests, ShapeFor(8, 192, 786432)); p.Nodes != 1 || p.GPUs != 8 {
+		t.Fatalf("g5.48xlarge: got %+v", p)
+	}
+	// g5.12xlarge: 4 GPUs, 48 vCPUs, 192 GiB; first-fit decreasing places the 2-GPU pod first.
+	mixed := append([]Request{oneGPU, {GPUs: 2, MilliCPU: 8000, MemoryB: 32 << 30}}, requests[:5]...)
+	if p := Pack(mixed, ShapeFor(4, 48, 196608)); p.Nodes != 2 || p.GPUs != 8 {
+		t.Fatalf("g5.12xlarge: got %+v", p)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package demand
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// labelNodePool is the node label Karpenter sets to the owning NodePool.
+const labelNodePool = "karpenter.sh/nodepool"
+
+// Request is the resources one pod requests.
+type Request struct {
+	GPUs     int64
+	MilliCPU int64
+	MemoryB  int64
+}
+
+// Shape is the allocatable capacity of one node.
+type Shape struct {
+	GPUs     int64
+	MilliCPU int64
+	MemoryB  int64
+}
+
+// ShapeFor approximates the allocatable capacity of an instance type: what
+// kube-reserved, system-reserved and eviction thresholds typically leave of
+// its vCPUs and memory.
+func ShapeFor(gpus, vcpus, memoryMiB int32) Shape {
+	return Shape{
+		GPUs:     int64(gpus),
+		MilliCPU: int64(vcpus) * 1000 * 94 / 100,
+		MemoryB:  int64(memoryMiB) << 20 * 90 / 100,
+	}
+}
+
+func (r Request) fits(free Shape) bool {
+	return r.GPUs <= free.GPUs && r.MilliCPU <= free.MilliCPU && r.MemoryB <= free.MemoryB
+}
+
+// Packing is the result of packing requests onto nodes of one shape.
+type Packing struct {
+	// Nodes needed for the requests that fit a node.
+	Nodes int
+	// Unplaced requests exceed a whole node.
+	Unplaced int
+	// GPUs requested by the placed requests.
+	GPUs int64
+}
+
+// Pack places requests first-fit decreasing (by GPUs, then CPU, then memory)
+// onto as few nodes of shape as it can.
+func Pack(requests []Request, shape Shape) Packing {
+	sorted := append([]Request(nil), requests...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.GPUs != b.GPUs {
+			return a.GPUs > b.GPUs
+		}
+		if a.MilliCPU != b.MilliCPU {
+			return a.MilliCPU > b.MilliCPU
+		}
+		return a.MemoryB > b.MemoryB
+	})
+
+	var p Packing
+	var free []Shape
+	for _, r := range sorted {
+		if !r.fits(shape) {
+			p.Unplaced++
+			continue
+		}
+		p.GPUs += r.GPUs
+		placed := false
+		for i := range free {
+			if r.fits(free[i]) {
+				free[i] = free[i].minus(r)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			free = append(free, shape.minus(r))
+		}
+	}
+	p.Nodes = len(free)
+	return p
+}
+
+func (s Shape) minus(r Request) Shape {
+	return Shape{GPUs: s.GPUs - r.GPUs, MilliCPU: s.MilliCPU - r.MilliCPU, MemoryB: s.MemoryB - r.MemoryB}
+}
+
+// Workload collects the requests of the pods a pool serves: unschedulable
+// pods that could land on its nodes and pods running on nodes of its
+// NodePools. Only pods requesting GPUs are included.
+type Workload struct {
+	Reader    client.Reader
+	Labels    map[string]string
+	Taints    []string
+	NodePools []string
+}
+
+// Requests lists the workload's pod requests.
+func (w *Workload) Requests(ctx context.Context) ([]Request, error) {
+	nodes := map[string]bool{}
+	for _, np := range w.NodePools {
+		var list corev1.NodeList
+		if err := w.Reader.List(ctx, &list, client.MatchingLabels{labelNodePool: np}); err != nil {
+			return nil, err
+		}
+		for _, n := range list.Items {
+			nodes[n.Name] = true
+		}
+	}
+
+	var pending, running corev1.PodList
+	if err := w.Reader.List(ctx, &pending, client.MatchingFields{PodPhaseField: string(corev1.PodPending)}); err != nil {
+		return nil, err
+	}
+	if len(nodes) > 0 {
+		if err := w.Reader.List(ctx, &running, client.MatchingFields{PodPhaseField: string(corev1.PodRunning)}); err != nil {
+			return nil, err
+		}
+	}
+
+	taints := parseTaints(w.Taints)
+	var out []Request
+	for i := range pending.Items {
+		pod := &pending.Items[i]
+		if unschedulable(pod) && schedulableOn(pod, w.Labels, taints) {
+			out = appendRequest(out, pod)
+		}
+	}
+	for i := range running.Items {
+		if pod := &running.Items[i]; nodes[pod.Spec.NodeName] {
+			out = appendRequest(out, pod)
+		}
+	}
+	return out, nil
+}
+
+func appendRequest(out []Request, pod *corev1.Pod) []Request {
+	r := podRequest(pod)
+	if r.GPUs == 0 {
+		return out
+	}
+	return append(out, r)
+}
+
+// podRequest returns the pod's effective requests: the larger of the summed
+// container requests and the largest init container request, per resource.
+func podRequest(pod *corev1.Pod) Request {
+	var containers, initContainers Request
+	for _, c := range pod.Spec.Containers {
+		containers.GPUs += containerGPUs(c.Resources)
+		containers.MilliCPU += c.Resources.Requests.Cpu().MilliValue()
+		containers.MemoryB += c.Resources.Requests.Memory().Value()
+	}
+	for _, c := range pod.Spec.InitContainers {
+		initContainers.GPUs = max(initContainers.GPUs, containerGPUs(c.Resources))
+		initContainers.MilliCPU = max(initContainers.MilliCPU, c.Resources.Requests.Cpu().MilliValue())
+		initContainers.MemoryB = max(initContainers.MemoryB, c.Resources.Requests.Memory().Value())
+	}
+	return Request{
+		GPUs:     max(containers.GPUs, initContainers.GPUs),
+		MilliCPU: max(containers.MilliCPU, initContainers.MilliCPU),
+		MemoryB:  max(containers.MemoryB, initContainers.MemoryB),
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package demand
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodPhaseField is the pod field index pods are listed by; the cache must
+// index it (see IndexPodPhase).
+const PodPhaseField = "status.phase"
+
+// GPUResources are the extended resources counted as GPU requests.
+var GPUResources = []corev1.ResourceName{"nvidia.com/gpu", "amd.com/gpu"}
+
+// wellKnownLabelDomains are node label domains set by Kubernetes, Karpenter
+// or EKS rather than spec.labels; pods selecting them are not excluded.
+var wellKnownLabelDomains = []string{
+	"kubernetes.io/", "k8s.io/", "karpenter.sh/", "karpenter.k8s.aws/", "eks.amazonaws.com/",
+}
+
+// PendingPods sums the GPU requests of unschedulable pods that could land on
+// the pool's nodes and converts them into nodes of GPUsPerNode GPUs.
+type PendingPods struct {
+	// Reader lists pods by PodPhaseField.
+	Reader client.Reader
+	// Labels and Taints of the pool's nodes; taints in key[=value]:Effect form.
+	Labels map[string]string
+	Taints []string
+	// GPUsPerNode converts GPU requests into nodes; values below 1 count as 1.
+	GPUsPerNode int32
+}
+
+// Name implements Source.
+func (p *PendingPods) Name() string { return "PendingPods" }
+
+// Nodes implements Source.
+func (p *PendingPods) Nodes(ctx context.Context) (int32, error) {
+	var pods corev1.PodList
+	if err := p.Reader.List(ctx, &pods, client.MatchingFields{PodPhaseField: string(corev1.PodPending)}); err != nil {
+		return 0, err
+	}
+	taints := parseTaints(p.Taints)
+	var gpus int64
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !unschedulable(pod) || !schedulableOn(pod, p.Labels, taints) {
+			continue
+		}
+		gpus += podRequest(pod).GPUs
+	}
+	perNode := int64(max(p.GPUsPerNode, 1))
+	return nodesFor(float64(gpus) / float64(perNode)), nil
+}
+
+// unschedulable reports whether the scheduler found no node for the pod.
+func unschedulable(pod *corev1.Pod) bool {
+	if pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled {
+			return c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable
+		}
+	}
+	return false
+}
+
+// IndexPodPhase indexes pods by PodPhaseField in the manager's cache.
+func IndexPodPhase(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &corev1.Pod{}, PodPhaseField, func(o client.Object) []string {
+		return []string{string(o.(*corev1.Pod).Status.Phase)}
+	})
+}
+
+// schedulableOn reports whether the pod's node selector, required node
+// affinity and tolerations admit a node with the given labels and taints.
+// Selected labels outside the well-known domains must be among the pool
+// labels; well-known labels are assumed to match.
+func schedulableOn(pod *corev1.Pod, labels map[string]string, taints []corev1.Taint) bool {
+	for k, v := range pod.Spec.NodeSelector {
+		if got, ok := labels[k]; ok {
+			if got != v {
+				return false
+			}
+		} else if !wellKnownLabel(k) {
+			return false
+		}
+	}
+	if !affinityAdmits(pod.Spec.Affinity, labels) {
+		return false
+	}
+	for i := range taints {
+		if taints[i].Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !tolerates(pod.Spec.Tolerations, &taints[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// affinityAdmits reports whether any required node selector term admits a
+// node with the given labels.
+func affinityAdmits(a *corev1.Affinity, labels map[string]string) bool {
+	if a == nil || a.NodeAffinity == nil || a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if termAdmits(term, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// termAdmits ANDs the term's label expressions; an empty term matches no
+// node. Field expressions select node names and are assumed to match.
+func termAdmits(term corev1.NodeSelectorTerm, labels map[string]string) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, req := range term.MatchExpressions {
+		if !requirementAdmits(req, labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func requirementAdmits(req corev1.NodeSelectorRequirement, labels map[string]string) bool {
+	v, ok := labels[req.Key]
+	if !ok {
+		if wellKnownLabel(req.Key) {
+			return true
+		}
+		return req.Operator == corev1.NodeSelectorOpNotIn || req.Operator == corev1.NodeSelectorOpDoesNotExist
+	}
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		return slices.Contains(req.Values, v)
+	case corev1.NodeSelectorOpNotIn:
+		return !slices.Contains(req.Values, v)
+	case corev1.NodeSelectorOpExists:
+		return true
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if len(req.Values) != 1 {
+			return false
+		}
+		got, err1 := strconv.ParseInt(v, 10, 64)
+		want, err2 := strconv.ParseInt(req.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		return req.Operator == corev1.NodeSelectorOpGt && got > want || req.Operator == corev1.NodeSelectorOpLt && got < want
+	}
+	return false
+}
+
+func tolerates(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+func wellKnownLabel(key string) bool {
+	for _, d := range wellKnownLabelDomains {
+		if strings.Contains(key, d) {
+			return true
+		}
+	}
+	return false
+}
+
+func containerGPUs(r corev1.ResourceRequirements) int64 {
+	var n int64
+	for _, name := range GPUResources {
+		q, ok := r.Requests[name]
+		if !ok {
+			q, ok = r.Limits[name]
+		}
+		if ok {
+			n += q.Value()
+		}
+	}
+	return n
+}
+
+// parseTaints parses key[=value]:Effect taints, skipping malformed entries.
+func parseTaints(specs []string) []corev1.Taint {
+	var out []corev1.Taint
+	for _, s := range specs {
+		kv, effect, ok := strings.Cut(s, ":")
+		if !ok {
+			continue
+		}
+		key, value, _ := strings.Cut(kv, "=")
+		if key == "" {
+			continue
+		}
+		switch e := corev1.TaintEffect(effect); e {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+			out = append(out, corev1.Taint{Key: key, Value: value, Effect: e})
+		}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package demand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// QueryTimeout bounds one PromQL query.
+const QueryTimeout = 10 * time.Second
+
+// ErrNoPrometheus is returned when a Prometheus source is used without a
+// configured endpoint.
+var ErrNoPrometheus = errors.New("no Prometheus endpoint configured (--prometheus-url)")
+
+// PrometheusClient runs instant PromQL queries against one endpoint.
+type PrometheusClient struct {
+	api promv1.API
+}
+
+// NewPrometheusClient returns a client for the Prometheus HTTP API at url, or
+// nil when url is empty.
+func NewPrometheusClient(url string) (*PrometheusClient, error) {
+	if url == "" {
+		return nil, nil
+	}
+	c, err := promapi.NewClient(promapi.Config{Address: url})
+	if err != nil {
+		return nil, err
+	}
+	return &PrometheusClient{api: promv1.NewAPI(c)}, nil
+}
+
+// Prometheus reads the number of nodes needed from a PromQL query. The query
+// must return a scalar or a single-sample vector; an empty vector means no
+// demand. Fractional results are rounded up.
+type Prometheus struct {
+	Client *PrometheusClient
+	Query  string
+}
+
+// Name implements Source.
+func (p *Prometheus) Name() string { return "Prometheus" }
+
+// Nodes implements Source.
+func (p *Prometheus) Nodes(ctx context.Context) (int32, error) {
+	if p.Client == nil {
+		return 0, ErrNoPrometheus
+	}
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+	v, _, err := p.Client.api.Query(ctx, p.Query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("query %q: %w", p.Query, err)
+	}
+	n, err := sampleValue(v)
+	if err != nil {
+		return 0, fmt.Errorf("query %q: %w", p.Query, err)
+	}
+	return nodesFor(n), nil
+}
+
+func sampleValue(v model.Value) (float64, error) {
+	switch v := v.(type) {
+	case *model.Scalar:
+		return float64(v.Value), nil
+	case model.Vector:
+		switch len(v) {
+		case 0:
+			return 0, nil
+		case 1:
+			return float64(v[0].Value), nil
+		}
+		return 0, fmt.Errorf("got %d samples, want one", len(v))
+	}
+	return 0, fmt.Errorf("unsupported result type %s", v.Type())
+}
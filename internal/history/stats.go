@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultForecastHours is the forecast horizon used for exported metrics.
+const DefaultForecastHours = 6
+
+// Stats summarizes the price history of one offering. The history is sampled
+// hourly, so an offering whose price held for six days weighs six days.
+type Stats struct {
+	// Samples is the number of hourly samples the statistics are based on.
+	Samples int
+	// Window is the time span covered by the samples.
+	Window time.Duration
+	// CurrentUSD is the latest known price.
+	CurrentUSD float64
+	// MeanUSD is the average price over the window.
+	MeanUSD float64
+	// Volatility is the coefficient of variation (stddev / mean) over the window.
+	Volatility float64
+	// TrendPerDay is the relative price change over the last 24h (0.1 = +10%/day).
+	TrendPerDay float64
+	// ForecastUSD is an EWMA of the hourly samples whose span matches the
+	// forecast horizon, i.e. the expected average price over that horizon.
+	ForecastUSD float64
+}
+
+// Penalized returns price raised by penaltyPercent for each 1.0 of volatility,
+// so a pool with a 20% coefficient of variation and a 50% penalty costs 10% more.
+func (s Stats) Penalized(price float64, penaltyPercent int) float64 {
+	if penaltyPercent <= 0 {
+		return price
+	}
+	return price * (1 + float64(penaltyPercent)/100*s.Volatility)
+}
+
+// compute samples pts hourly from the later of their first point and
+// horizon up to now.
+func compute(pts []Point, now, horizon time.Time, forecastHours int) Stats {
+	first := time.Unix(pts[0].Time, 0)
+	if first.Before(horizon) {
+		first = horizon
+	}
+	if now.Before(first) {
+		now = first
+	}
+	hours := int(now.Sub(first) / time.Hour)
+	samples := make([]float64, 0, hours+1)
+	for k := hours; k >= 0; k-- {
+		samples = append(samples, priceAt(pts, now.Add(-time.Duration(k)*time.Hour).Unix()))
+	}
+
+	st := Stats{
+		Samples:    len(samples),
+		Window:     time.Duration(hours) * time.Hour,
+		CurrentUSD: pts[len(pts)-1].PriceUSD,
+	}
+
+	var sum float64
+	for _, v := range samples {
+		sum += v
+	}
+	st.MeanUSD = sum / float64(len(samples))
+	if st.MeanUSD > 0 {
+		var sq float64
+		for _, v := range samples {
+			sq += (v - st.MeanUSD) * (v - st.MeanUSD)
+		}
+		st.Volatility = math.Sqrt(sq/float64(len(samples))) / st.MeanUSD
+	}
+
+	// Trend compares the newest sample with the one 24h before it, scaled to a
+	// day when less history is available.
+	back := min(24, len(samples)-1)
+	if back > 0 {
+		from := samples[len(samples)-1-back]
+		if from > 0 {
+			st.TrendPerDay = (samples[len(samples)-1] - from) / from * 24 / float64(back)
+		}
+	}
+
+	if forecastHours < 1 {
+		forecastHours = 1
+	}
+	alpha := 2 / (float64(forecastHours) + 1)
+	ewma := samples[0]
+	for _, v := range samples[1:] {
+		ewma = alpha*v + (1-alpha)*ewma
+	}
+	st.ForecastUSD = ewma
+	return st
+}
+
+// priceAt returns the price in effect at unix time t (pts must start at or before t).
+func priceAt(pts []Point, t int64) float64 {
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].Time > t })
+	if i == 0 {
+		return pts[0].PriceUSD
+	}
+	return pts[i-1].PriceUSD
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/metrics"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestComputeFlatPrice(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	pts := []Point{{Time: now.Add(-48 * time.Hour).Unix(), PriceUSD: 1.0}}
+
+	st := compute(pts, now, time.Time{}, 6)
+	if st.Samples != 49 || st.Window != 48*time.Hour {
+		t.Fatalf("samples=%d window=%s", st.Samples, st.Window)
+	}
+	if st.Volatility != 0 || st.TrendPerDay != 0 || !approx(st.ForecastUSD, 1.0) || !approx(st.MeanUSD, 1.0) {
+		t.Fatalf("unexpected stats for a flat price: %+v", st)
+	}
+}
+
+func TestComputeStepUp(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	pts := []Point{
+		{Time: now.Add(-48 * time.Hour).Unix(), PriceUSD: 1.0},
+		{Time: now.Add(-12 * time.Hour).Unix(), PriceUSD: 2.0},
+	}
+
+	st := compute(pts, now, time.Time{}, 6)
+	if !approx(st.CurrentUSD, 2.0) {
+		t.Fatalf("current=%v", st.CurrentUSD)
+	}
+	// 24h ago the price was 1.0, now 2.0.
+	if !approx(st.TrendPerDay, 1.0) {
+		t.Fatalf("trend=%v", st.TrendPerDay)
+	}
+	if st.Volatility <= 0 {
+		t.Fatalf("volatility=%v", st.Volatility)
+	}
+	// Twelve hours at the new price with a 6h span leave the EWMA close to it.
+	if st.ForecastUSD <= 1.9 || st.ForecastUSD > 2.0 {
+		t.Fatalf("forecast=%v", st.ForecastUSD)
+	}
+	if long := compute(pts, now, time.Time{}, 72); long.ForecastUSD >= st.ForecastUSD {
+		t.Fatalf("longer horizon should smooth more: %v >= %v", long.ForecastUSD, st.ForecastUSD)
+	}
+}
+
+func TestPenalized(t *testing.T) {
+	st := Stats{Volatility: 0.2}
+	if got := st.Penalized(1.0, 50); !approx(got, 1.1) {
+		t.Fatalf("penalized=%v", got)
+	}
+	if got := st.Penalized(1.0, 0); got != 1.0 {
+		t.Fatalf("penalized without penalty=%v", got)
+	}
+}
+
+func TestAddKeepsOnlyChanges(t *testing.T) {
+	h := &regionHistory{series: map[string][]Point{}}
+	at := func(hour int, price float64) awsx.SpotQuote {
+		return awsx.SpotQuote{InstanceType: "g5.xlarge", Zone: "us-east-1a", PriceUSD: price, Timestamp: time.Unix(int64(hour)*3600, 0)}
+	}
+
+	h.add(at(1, 1.0))
+	if h.add(at(2, 1.0)) {
+		t.Fatal("repeated price must not be recorded")
+	}
+	h.add(at(4, 2.0))
+	// Backfilled out of order: 1.0 at hour 3 is not a change.
+	if h.add(at(3, 1.0)) {
+		t.Fatal("out-of-order repeat must not be recorded")
+	}
+	// 2.0 at hour 3 moves the change earlier and makes hour 4 redundant.
+	h.add(at(3, 2.0))
+
+	pts := h.series["g5.xlarge/us-east-1a"]
+	if len(pts) != 2 || pts[1].Time != 3*3600 || pts[1].PriceUSD != 2.0 {
+		t.Fatalf("series=%v", pts)
+	}
+}
+
+func TestPruneKeepsLeadingPoint(t *testing.T) {
+	h := &regionHistory{series: map[string][]Point{
+		"a": {{Time: 10, PriceUSD: 1}, {Time: 20, PriceUSD: 2}, {Time: 30, PriceUSD: 3}},
+	}}
+	if got := h.prune(time.Unix(25, 0)); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("pruned types = %v", got)
+	}
+	if pts := h.series["a"]; len(pts) != 2 || pts[0].Time != 20 {
+		t.Fatalf("series=%v", pts)
+	}
+}
+
+func TestComputeClampsWindowToHorizon(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	// The leading point kept by prune is 30 days old; a 7 day retention
+	// must not stretch the window to it.
+	pts := []Point{
+		{Time: now.Add(-30 * 24 * time.Hour).Unix(), PriceUSD: 5.0},
+		{Time: now.Add(-24 * time.Hour).Unix(), PriceUSD: 1.0},
+	}
+	st := compute(pts, now, now.Add(-7*24*time.Hour), 6)
+	if st.Window != 7*24*time.Hour || st.Samples != 7*24+1 {
+		t.Fatalf("window=%s samples=%d", st.Window, st.Samples)
+	}
+	// 6 days at 5.0 and 1 day (plus the current sample) at 1.0.
+	if want := (6*24*5.0 + 25*1.0) / float64(st.Samples); !approx(st.MeanUSD, want) {
+		t.Fatalf("mean=%v, want %v", st.MeanUSD, want)
+	}
+}
+
+func TestAddCapsSeries(t *testing.T) {
+	h := &regionHistory{series: map[string][]Point{}}
+	for i := range maxPoints + 10 {
+		h.add(awsx.SpotQuote{InstanceType: "g5.xlarge", Zone: "a", PriceUSD: float64(i % 2), Timestamp: time.Unix(int64(i), 0)})
+	}
+	pts := h.series["g5.xlarge/a"]
+	if len(pts) != maxPoints || pts[len(pts)-1].Time != int64(maxPoints+9) {
+		t.Fatalf("len=%d last=%v", len(pts), pts[len(pts)-1])
+	}
+}
+
+// fakeSource records backfill requests and returns no history.
+type fakeSource struct {
+	types  [][]string
+	starts []time.Time
+}
+
+func (f *fakeSource) SpotPriceHistory(_ context.Context, _ string, instanceTypes []string, start time.Time) ([]awsx.SpotQuote, error) {
+	f.types = append(f.types, instanceTypes)
+	f.starts = append(f.starts, start)
+	return nil, nil
+}
+
+func TestObserveBackfillsFromOldestOffering(t *testing.T) {
+	s := NewStore(nil, nil, "", 7*24*time.Hour)
+	now := time.Now()
+	h := s.region(historyKey("us-east-1", ""))
+	// Zone a is up to date, zone b has an old point only.
+	h.series["g5.xlarge/us-east-1a"] = []Point{{Time: now.Add(-time.Minute).Unix(), PriceUSD: 1}}
+	h.series["g5.xlarge/us-east-1b"] = []Point{{Time: now.Add(-48 * time.Hour).Unix(), PriceUSD: 1}}
+
+	quotes := map[[2]string]awsx.SpotQuote{}
+	for _, z := range []string{"us-east-1a", "us-east-1b"} {
+		quotes[[2]string{"g5.xlarge", z}] = awsx.SpotQuote{InstanceType: "g5.xlarge", Zone: z, PriceUSD: 1, Timestamp: now}
+	}
+	src := &fakeSource{}
+	if err := s.Observe(context.Background(), src, "us-east-1", "", quotes); err != nil {
+		t.Fatal(err)
+	}
+	if len(src.starts) != 1 || !reflect.DeepEqual(src.types[0], []string{"g5.xlarge"}) ||
+		src.starts[0].Unix() != now.Add(-48*time.Hour).Unix() {
+		t.Fatalf("backfill types=%v starts=%v", src.types, src.starts)
+	}
+	// Backfilled types are not requested again.
+	if err := s.Observe(context.Background(), src, "us-east-1", "", quotes); err != nil || len(src.starts) != 1 {
+		t.Fatalf("err=%v backfills=%d", err, len(src.starts))
+	}
+}
+
+func TestObservePersistsOneConfigMapPerType(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	s := NewStore(c, c, "leftover-system", 7*24*time.Hour)
+	now := time.Now()
+	quotes := map[[2]string]awsx.SpotQuote{
+		{"g5.xlarge", "us-east-1a"}: {InstanceType: "g5.xlarge", Zone: "us-east-1a", PriceUSD: 1, Timestamp: now},
+		{"g5.xlarge", "us-east-1b"}: {InstanceType: "g5.xlarge", Zone: "us-east-1b", PriceUSD: 2, Timestamp: now},
+		{"g6.xlarge", "us-east-1a"}: {InstanceType: "g6.xlarge", Zone: "us-east-1a", PriceUSD: 3, Timestamp: now},
+	}
+	if err := s.Observe(context.Background(), &fakeSource{}, "us-east-1", "Windows", quotes); err != nil {
+		t.Fatal(err)
+	}
+
+	var cms corev1.ConfigMapList
+	if err := c.List(context.Background(), &cms, client.InNamespace("leftover-system")); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, cm := range cms.Items {
+		names = append(names, cm.Name)
+	}
+	want := []string{"leftover-price-history-us-east-1-windows-g5.xlarge", "leftover-price-history-us-east-1-windows-g6.xlarge"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("ConfigMaps = %v, want %v", names, want)
+	}
+
+	// A new store loads each type's series from its ConfigMap.
+	restarted := NewStore(c, c, "leftover-system", 7*24*time.Hour)
+	if err := restarted.Observe(context.Background(), &fakeSource{}, "us-east-1", "Windows", quotes); err != nil {
+		t.Fatal(err)
+	}
+	h := restarted.region(historyKey("us-east-1", "Windows"))
+	if len(h.series) != 3 || h.series["g5.xlarge/us-east-1b"][0].PriceUSD != 2 {
+		t.Fatalf("series = %v", h.series)
+	}
+}
+
+func TestObserveRetriesTypesAfterFailedLoad(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	old := time.Now().Add(-time.Hour)
+	quotes := map[[2]string]awsx.SpotQuote{
+		{"g5.xlarge", "a"}:    {InstanceType: "g5.xlarge", Zone: "a", PriceUSD: 1, Timestamp: old},
+		{"g6.xlarge", "a"}:    {InstanceType: "g6.xlarge", Zone: "a", PriceUSD: 2, Timestamp: old},
+		{"p4d.24xlarge", "a"}: {InstanceType: "p4d.24xlarge", Zone: "a", PriceUSD: 3, Timestamp: old},
+	}
+	if err := NewStore(c, c, "ns", 0).Observe(context.Background(), &fakeSource{}, "us-east-1", "", quotes); err != nil {
+		t.Fatal(err)
+	}
+
+	// After a restart the load of g6.xlarge fails once.
+	failed := false
+	reader := fake.NewClientBuilder().WithObjects(configMaps(t, c)...).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if key.Name == "leftover-price-history-us-east-1-g6.xlarge" && !failed {
+				failed = true
+				return errors.New("boom")
+			}
+			return cl.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+	s := NewStore(c, reader, "ns", 0)
+	now := map[[2]string]awsx.SpotQuote{}
+	for k, q := range quotes {
+		q.PriceUSD, q.Timestamp = q.PriceUSD+1, time.Now()
+		now[k] = q
+	}
+	if err := s.Observe(context.Background(), &fakeSource{}, "us-east-1", "", now); err == nil {
+		t.Fatal("expected the load error")
+	}
+	if err := s.Observe(context.Background(), &fakeSource{}, "us-east-1", "", now); err != nil {
+		t.Fatal(err)
+	}
+	// The persisted points of the types after the failure survive.
+	h := s.region("us-east-1")
+	for _, it := range []string{"g5.xlarge", "g6.xlarge", "p4d.24xlarge"} {
+		if pts := h.series[it+"/a"]; len(pts) != 2 {
+			t.Errorf("%s: %d points, want the persisted and the new one", it, len(pts))
+		}
+	}
+}
+
+func configMaps(t *testing.T, c client.Client) []client.Object {
+	t.Helper()
+	var cms corev1.ConfigMapList
+	if err := c.List(context.Background(), &cms); err != nil {
+		t.Fatal(err)
+	}
+	out := make([]client.Object, 0, len(cms.Items))
+	for i := range cms.Items {
+		cms.Items[i].ResourceVersion = ""
+		out = append(out, &cms.Items[i])
+	}
+	return out
+}
+
+func TestObserveDeletesStaleMetrics(t *testing.T) {
+	s := NewStore(nil, nil, "", 0)
+	now := time.Now()
+	q := func(it string) awsx.SpotQuote {
+		return awsx.SpotQuote{InstanceType: it, Zone: "z", PriceUSD: 1, Timestamp: now}
+	}
+	both := map[[2]string]awsx.SpotQuote{{"g5.xlarge", "z"}: q("g5.xlarge"), {"g6.xlarge", "z"}: q("g6.xlarge")}
+	if err := s.Observe(context.Background(), &fakeSource{}, "metrics-test-1", "", both); err != nil {
+		t.Fatal(err)
+	}
+	one := map[[2]string]awsx.SpotQuote{{"g5.xlarge", "z"}: q("g5.xlarge")}
+	if err := s.Observe(context.Background(), &fakeSource{}, "metrics-test-1", "", one); err != nil {
+		t.Fatal(err)
+	}
+	if metrics.SpotPriceForecast.DeleteLabelValues("metrics-test-1", awsx.ProductLinux, "g6.xlarge", "z") {
+		t.Error("metric of the unquoted offering still exported")
+	}
+	if !metrics.SpotPriceForecast.DeleteLabelValues("metrics-test-1", awsx.ProductLinux, "g5.xlarge", "z") {
+		t.Error("metric of the quoted offering missing")
+	}
+}
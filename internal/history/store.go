@@ -0,0 +1,387 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package history keeps a rolling Spot price history per region and derives
+// volatility, trend and forecast signals from it. Histories are persisted to
+// one ConfigMap per region, product and instance type so they survive
+// operator restarts.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+	"github.com/devplatformsolutions/leftover/internal/metrics"
+)
+
+// DefaultRetention is how much price history is kept per offering.
+const DefaultRetention = 7 * 24 * time.Hour
+
+const (
+	configMapPrefix = "leftover-price-history-"
+	configMapKey    = "history.json"
+	// maxPoints caps a series, so a type's ConfigMap stays far below the
+	// 1 MiB object limit however often its prices change.
+	maxPoints = 2048
+)
+
+// Point is one price change. Spot prices are a step function, so a series
+// only records the times at which the price changed.
+type Point struct {
+	Time     int64   `json:"t"`
+	PriceUSD float64 `json:"p"`
+}
+
+// Source fetches historical Spot prices; *awsx.Client implements it.
+type Source interface {
+	SpotPriceHistory(ctx context.Context, product string, instanceTypes []string, start time.Time) ([]awsx.SpotQuote, error)
+}
+
+// Store holds the price histories of all regions and product descriptions.
+type Store struct {
+	writer    client.Client
+	reader    client.Reader
+	namespace string
+	retention time.Duration
+
+	mu      sync.Mutex
+	regions map[string]*regionHistory
+}
+
+type regionHistory struct {
+	mu         sync.Mutex
+	series     map[string][]Point // "type/zone" -> points, oldest first
+	loaded     map[string]bool    // instance types read from their ConfigMap
+	backfilled map[string]bool    // instance types backfilled by this process
+	exported   map[[2]string]bool // offerings (type, zone) with exported stats metrics
+}
+
+// NewStore returns a store persisting to ConfigMaps in namespace. reader should
+// be an uncached reader so the operator does not need to watch ConfigMaps.
+// An empty namespace keeps history in memory only.
+func NewStore(writer client.Client, reader client.Reader, namespace string, retention time.Duration) *Store {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Store{
+		writer:    writer,
+		reader:    reader,
+		namespace: namespace,
+		retention: retention,
+		regions:   make(map[string]*regionHistory),
+	}
+}
+
+func seriesKey(instanceType, zone string) string { return instanceType + "/" + zone }
+
+// seriesType returns the instance type of a series key.
+func seriesType(key string) string {
+	it, _, _ := strings.Cut(key, "/")
+	return it
+}
+
+// historyKey names the history of product in region; Linux keeps the bare region.
+func historyKey(region, product string) string {
+	if slug := awsx.ProductSlug(product); slug != "" {
+		return region + "-" + slug
+	}
+	return region
+}
+
+func (s *Store) region(key string) *regionHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.regions[key]
+	if !ok {
+		h = &regionHistory{series: make(map[string][]Point), loaded: make(map[string]bool), backfilled: make(map[string]bool), exported: make(map[[2]string]bool)}
+		s.regions[key] = h
+	}
+	return h
+}
+
+// Observe records the latest quotes of product in a region. On first sight of
+// an instance type its persisted history is loaded, and the history since
+// the last point of each of its offerings (or the whole retention window) is
+// backfilled from src. Changes are persisted before returning.
+func (s *Store) Observe(ctx context.Context, src Source, region, product string, quotes map[[2]string]awsx.SpotQuote) error {
+	key := historyKey(region, product)
+	h := s.region(key)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := s.loadTypes(ctx, key, h, quotes); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	horizon := now.Add(-s.retention)
+	changed := make(map[string]bool) // instance types whose series changed
+
+	// The backfill starts at the oldest last point of any offering (type
+	// and zone) not backfilled yet.
+	var missing []string
+	start := now
+	seen := make(map[string]bool)
+	for _, q := range quotes {
+		if h.backfilled[q.InstanceType] {
+			continue
+		}
+		if !seen[q.InstanceType] {
+			seen[q.InstanceType] = true
+			missing = append(missing, q.InstanceType)
+		}
+		since := horizon
+		if pts := h.series[seriesKey(q.InstanceType, q.Zone)]; len(pts) > 0 {
+			since = time.Unix(pts[len(pts)-1].Time, 0)
+		}
+		if since.Before(start) {
+			start = since
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		past, err := src.SpotPriceHistory(ctx, product, missing, start)
+		if err != nil {
+			return fmt.Errorf("backfilling price history: %w", err)
+		}
+		for _, q := range past {
+			if h.add(q) {
+				changed[q.InstanceType] = true
+			}
+		}
+		for _, it := range missing {
+			h.backfilled[it] = true
+		}
+	}
+
+	for _, q := range quotes {
+		if h.add(q) {
+			changed[q.InstanceType] = true
+		}
+	}
+	for _, it := range h.prune(horizon) {
+		changed[it] = true
+	}
+
+	h.export(region, product, quotes, now, horizon)
+
+	dirty := make([]string, 0, len(changed))
+	for it := range changed {
+		dirty = append(dirty, it)
+	}
+	sort.Strings(dirty)
+	for _, it := range dirty {
+		if err := s.save(ctx, key, it, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTypes loads the persisted history of every quoted instance type not
+// loaded yet. A type counts as loaded only once its ConfigMap has been read,
+// so a failed load is retried instead of its history being overwritten.
+func (s *Store) loadTypes(ctx context.Context, key string, h *regionHistory, quotes map[[2]string]awsx.SpotQuote) error {
+	types := make([]string, 0, len(quotes))
+	seen := make(map[string]bool)
+	for _, q := range quotes {
+		if !h.loaded[q.InstanceType] && !seen[q.InstanceType] {
+			seen[q.InstanceType] = true
+			types = append(types, q.InstanceType)
+		}
+	}
+	sort.Strings(types)
+	for _, it := range types {
+		if err := s.load(ctx, key, it, h); err != nil {
+			return err
+		}
+		h.loaded[it] = true
+	}
+	return nil
+}
+
+// export sets the stats metrics of the quoted offerings and deletes those of
+// offerings no longer quoted or without history.
+func (h *regionHistory) export(region, product string, quotes map[[2]string]awsx.SpotQuote, now, horizon time.Time) {
+	label := product
+	if label == "" {
+		label = awsx.ProductLinux
+	}
+	current := make(map[[2]string]bool, len(quotes))
+	for _, q := range quotes {
+		st, ok := h.stats(seriesKey(q.InstanceType, q.Zone), now, horizon, DefaultForecastHours)
+		if !ok {
+			continue
+		}
+		current[[2]string{q.InstanceType, q.Zone}] = true
+		metrics.SpotPriceVolatility.WithLabelValues(region, label, q.InstanceType, q.Zone).Set(st.Volatility)
+		metrics.SpotPriceTrend.WithLabelValues(region, label, q.InstanceType, q.Zone).Set(st.TrendPerDay)
+		metrics.SpotPriceForecast.WithLabelValues(region, label, q.InstanceType, q.Zone).Set(st.ForecastUSD)
+	}
+	for o := range h.exported {
+		if !current[o] {
+			metrics.SpotPriceVolatility.DeleteLabelValues(region, label, o[0], o[1])
+			metrics.SpotPriceTrend.DeleteLabelValues(region, label, o[0], o[1])
+			metrics.SpotPriceForecast.DeleteLabelValues(region, label, o[0], o[1])
+		}
+	}
+	h.exported = current
+}
+
+// Stats returns the statistics of one offering with a forecast over
+// forecastHours. ok is false when nothing is known about the offering.
+func (s *Store) Stats(region, product, instanceType, zone string, forecastHours int) (Stats, bool) {
+	h := s.region(historyKey(region, product))
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	return h.stats(seriesKey(instanceType, zone), now, now.Add(-s.retention), forecastHours)
+}
+
+// stats computes the statistics of a series over the window starting at
+// horizon, or at its first point if later.
+func (h *regionHistory) stats(key string, now, horizon time.Time, forecastHours int) (Stats, bool) {
+	pts := h.series[key]
+	if len(pts) == 0 {
+		return Stats{}, false
+	}
+	return compute(pts, now, horizon, forecastHours), true
+}
+
+// add inserts q into its series, keeping only price changes. It reports whether
+// the series changed.
+func (h *regionHistory) add(q awsx.SpotQuote) bool {
+	key := seriesKey(q.InstanceType, q.Zone)
+	pts := h.series[key]
+	p := Point{Time: q.Timestamp.Unix(), PriceUSD: q.PriceUSD}
+
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].Time >= p.Time })
+	if i < len(pts) && pts[i].Time == p.Time {
+		return false
+	}
+	if i > 0 && pts[i-1].PriceUSD == p.PriceUSD {
+		return false
+	}
+	pts = append(pts, Point{})
+	copy(pts[i+1:], pts[i:])
+	pts[i] = p
+	// A point equal to its new predecessor no longer marks a change.
+	if i+1 < len(pts) && pts[i+1].PriceUSD == p.PriceUSD {
+		pts = append(pts[:i+1], pts[i+2:]...)
+	}
+	if len(pts) > maxPoints {
+		pts = append([]Point(nil), pts[len(pts)-maxPoints:]...)
+	}
+	h.series[key] = pts
+	return true
+}
+
+// prune drops points older than horizon, keeping the last one before it since
+// it still defines the price at the start of the window. It returns the
+// instance types whose series changed.
+func (h *regionHistory) prune(horizon time.Time) []string {
+	var changed []string
+	cut := horizon.Unix()
+	for key, pts := range h.series {
+		i := sort.Search(len(pts), func(i int) bool { return pts[i].Time > cut })
+		if i > 1 {
+			h.series[key] = append([]Point(nil), pts[i-1:]...)
+			changed = append(changed, seriesType(key))
+		}
+	}
+	return changed
+}
+
+type persisted struct {
+	Series map[string][]Point `json:"series"`
+}
+
+// configMapName names the ConfigMap of instanceType's history under key.
+func (s *Store) configMapName(key, instanceType string) string {
+	return configMapPrefix + key + "-" + instanceType
+}
+
+// load reads the persisted series of instanceType.
+func (s *Store) load(ctx context.Context, key, instanceType string, h *regionHistory) error {
+	if s.namespace == "" || s.reader == nil {
+		return nil
+	}
+	var cm corev1.ConfigMap
+	err := s.reader.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.configMapName(key, instanceType)}, &cm)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("loading price history: %w", err)
+	}
+	var p persisted
+	if err := json.Unmarshal([]byte(cm.Data[configMapKey]), &p); err != nil {
+		// A corrupt history is rebuilt from the EC2 backfill rather than blocking selection.
+		logf.FromContext(ctx).Error(err, "discarding unreadable price history", "configMap", cm.Name)
+		return nil
+	}
+	for k, pts := range p.Series {
+		if seriesType(k) == instanceType {
+			h.series[k] = pts
+		}
+	}
+	return nil
+}
+
+// save persists the series of instanceType.
+func (s *Store) save(ctx context.Context, key, instanceType string, h *regionHistory) error {
+	if s.namespace == "" || s.writer == nil {
+		return nil
+	}
+	series := make(map[string][]Point)
+	for k, pts := range h.series {
+		if seriesType(k) == instanceType {
+			series[k] = pts
+		}
+	}
+	raw, err := json.Marshal(persisted{Series: series})
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.configMapName(key, instanceType),
+			Namespace: s.namespace,
+			Labels:    map[string]string{"managed-by": "leftover"},
+		},
+		Data: map[string]string{configMapKey: string(raw)},
+	}
+	err = s.writer.Update(ctx, cm)
+	if apierrors.IsNotFound(err) {
+		err = s.writer.Create(ctx, cm)
+	}
+	if err != nil {
+		return fmt.Errorf("saving price history: %w", err)
+	}
+	return nil
+}
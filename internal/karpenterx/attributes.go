@@ -0,0 +1,61 @@
+package karpenterx
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// attributeLabels are the well-known labels attribute-based requirements
+// constrain, in rendering order.
+var attributeLabels = []string{
+	LabelInstanceGPUName,
+	LabelInstanceGPUCount,
+	LabelInstanceGPUMemory,
+	LabelInstanceFamily,
+	LabelInstanceGeneration,
+}
+
+// AddAttributes allows instance types sharing the attribute labels in labels
+// (see InstanceLabels) and makes the NodePool require attributes instead of
+// instance type names. Values of several calls are united per label.
+func (p *NodePoolParams) AddAttributes(labels map[string]string) {
+	if p.Attributes == nil {
+		p.Attributes = map[string][]string{}
+	}
+	for _, key := range attributeLabels {
+		if v := labels[key]; v != "" && !slices.Contains(p.Attributes[key], v) {
+			p.Attributes[key] = append(p.Attributes[key], v)
+		}
+	}
+}
+
+// attributeRequirements renders the attribute requirements of p in
+// attributeLabels order.
+func attributeRequirements(p NodePoolParams) []corev1.NodeSelectorRequirement {
+	out := make([]corev1.NodeSelectorRequirement, 0, len(attributeLabels))
+	for _, key := range attributeLabels {
+		if values := p.Attributes[key]; len(values) > 0 {
+			out = append(out, corev1.NodeSelectorRequirement{Key: key, Operator: corev1.NodeSelectorOpIn, Values: values})
+		}
+	}
+	return out
+}
+
+// nodeAttributes returns the attribute labels of a node, reading the
+// eks.amazonaws.com equivalents on Auto Mode nodes.
+func nodeAttributes(labels map[string]string) map[string]string {
+	out := make(map[string]string, len(attributeLabels))
+	for _, key := range attributeLabels {
+		v := labels[key]
+		if v == "" {
+			if auto, ok := autoModeKey(key); ok {
+				v = labels[auto]
+			}
+		}
+		if v != "" {
+			out[key] = v
+		}
+	}
+	return out
+}
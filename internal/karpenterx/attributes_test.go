@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+)
+
+func TestAttributeRequirements(t *testing.T) {
+	p := NodePoolParams{InstanceTypes: []string{"g6.xlarge"}, Zones: []string{"us-east-1a"}, CapacityType: "spot", Architectures: []string{"amd64"}}
+	p.AddAttributes(InstanceLabels(awsx.InstanceMeta{Type: "g6.xlarge", GPUCount: 1, GPUMemMiB: 22888, GPUName: "L4", GPUManufacturer: "NVIDIA"}))
+	// A retained g6e node, as labeled on an Auto Mode cluster.
+	p.AddAttributes(nodeAttributes(map[string]string{
+		"eks.amazonaws.com/instance-gpu-name":   "l40s",
+		"eks.amazonaws.com/instance-gpu-count":  "1",
+		"eks.amazonaws.com/instance-gpu-memory": "45776",
+		"eks.amazonaws.com/instance-family":     "g6e",
+		"karpenter.k8s.aws/instance-generation": "6",
+	}))
+
+	cases := []struct {
+		name     string
+		autoMode bool
+		prefix   string
+	}{
+		{"karpenter", false, "karpenter.k8s.aws/"},
+		{"auto mode", true, "eks.amazonaws.com/"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := map[string]any{}
+			for _, r := range requirements(p, tc.autoMode) {
+				m := r.(map[string]any)
+				got[m["key"].(string)] = m["values"]
+			}
+			want := map[string]any{
+				"kubernetes.io/arch":              []any{"amd64"},
+				"karpenter.sh/capacity-type":      []any{"spot"},
+				"topology.kubernetes.io/zone":     []any{"us-east-1a"},
+				tc.prefix + "instance-gpu-name":   []any{"l4", "l40s"},
+				tc.prefix + "instance-gpu-count":  []any{"1"},
+				tc.prefix + "instance-gpu-memory": []any{"22888", "45776"},
+				tc.prefix + "instance-family":     []any{"g6", "g6e"},
+				tc.prefix + "instance-generation": []any{"6"},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("requirements = %v, want %v", got, want)
+			}
+		})
+	}
+}
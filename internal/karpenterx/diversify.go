@@ -0,0 +1,74 @@
+package karpenterx
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// RankWeight is the NodePool weight of the offering at rank (0-based) among
+// n diversified NodePools; the best offering gets the highest weight.
+func RankWeight(rank, n int) int32 {
+	return int32(n - rank)
+}
+
+// SplitLimits divides total over n NodePools in proportion to the first n
+// shares, or equally without shares. Parts are rounded to whole units when
+// the total is whole and by largest remainder, so they add up to the total;
+// ties go to the better ranked pool.
+func SplitLimits(total corev1.ResourceList, shares []int32, n int) []corev1.ResourceList {
+	if n <= 0 || len(total) == 0 {
+		return make([]corev1.ResourceList, max(n, 0))
+	}
+	weights := make([]int64, n)
+	var sum int64
+	for i := range weights {
+		weights[i] = 1
+		if i < len(shares) {
+			weights[i] = int64(max(shares[i], 0))
+		}
+		sum += weights[i]
+	}
+	out := make([]corev1.ResourceList, n)
+	for i := range out {
+		out[i] = corev1.ResourceList{}
+	}
+	if sum == 0 {
+		return out
+	}
+	for name, q := range total {
+		milli := q.MilliValue()
+		unit := int64(1)
+		if milli%1000 == 0 {
+			unit = 1000
+		}
+		for i, part := range largestRemainder(milli/unit, weights, sum) {
+			out[i][name] = *resource.NewMilliQuantity(part*unit, q.Format)
+		}
+	}
+	return out
+}
+
+// largestRemainder splits units in proportion to weights, which sum to sum:
+// each part is rounded down and the units left over go to the parts with the
+// largest remainders.
+func largestRemainder(units int64, weights []int64, sum int64) []int64 {
+	parts := make([]int64, len(weights))
+	rems := make([]int64, len(weights))
+	left := units
+	for i, w := range weights {
+		parts[i] = units * w / sum
+		rems[i] = units * w % sum
+		left -= parts[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rems[order[a]] > rems[order[b]] })
+	for _, i := range order[:left] {
+		parts[i]++
+	}
+	return parts
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestSplitLimits(t *testing.T) {
+	total := corev1.ResourceList{
+		"nvidia.com/gpu":   resource.MustParse("32"),
+		corev1.ResourceCPU: resource.MustParse("1500m"),
+	}
+	cases := []struct {
+		name   string
+		shares []int32
+		n      int
+		gpus   []string
+		cpu    []string
+	}{
+		{"weighted", []int32{50, 30, 20}, 3, []string{"16", "10", "6"}, []string{"750m", "450m", "300m"}},
+		{"equal", nil, 3, []string{"11", "11", "10"}, []string{"500m", "500m", "500m"}},
+		{"fewer offerings than pools", []int32{50, 30, 20}, 2, []string{"20", "12"}, []string{"938m", "562m"}},
+		{"zero share", []int32{0, 1}, 2, []string{"0", "32"}, []string{"0", "1500m"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SplitLimits(total, tc.shares, tc.n)
+			if len(got) != tc.n {
+				t.Fatalf("len = %d, want %d", len(got), tc.n)
+			}
+			var gpus, cpu resource.Quantity
+			for i := range got {
+				gpu := got[i]["nvidia.com/gpu"]
+				c := got[i][corev1.ResourceCPU]
+				if gpu.String() != tc.gpus[i] || c.String() != tc.cpu[i] {
+					t.Errorf("pool %d = gpu %s cpu %s, want gpu %s cpu %s", i, gpu.String(), c.String(), tc.gpus[i], tc.cpu[i])
+				}
+				gpus.Add(gpu)
+				cpu.Add(c)
+			}
+			if gpus.Cmp(total["nvidia.com/gpu"]) != 0 || cpu.Cmp(total[corev1.ResourceCPU]) != 0 {
+				t.Errorf("parts add up to gpu %s cpu %s", gpus.String(), cpu.String())
+			}
+		})
+	}
+}
+
+func TestRenderWeightAndLimits(t *testing.T) {
+	p := NodePoolParams{
+		Name: "leftover-a-1", NodeClassName: "gpu", InstanceTypes: []string{"g6.xlarge"}, Zones: []string{"us-east-1a"},
+		CapacityType: "spot", Architectures: []string{"amd64"}, Weight: 3,
+		Limits: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("16")},
+	}
+	for _, version := range []string{APIVersionV1, APIVersionV1beta1} {
+		api, err := NewAPI(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec := api.render.nodePoolSpec(api.nodeClassGVK, p)
+		if spec["weight"] != int64(3) {
+			t.Errorf("%s: weight = %v, want 3", version, spec["weight"])
+		}
+		limits, _ := spec["limits"].(map[string]any)
+		if limits["nvidia.com/gpu"] != "16" {
+			t.Errorf("%s: limits = %v, want nvidia.com/gpu: 16", version, limits)
+		}
+	}
+}
@@ -3,138 +3,215 @@ package karpenterx
 import (
 	"context"
 	"fmt"
-	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-
-	"github.com/go-logr/logr"
 )
 
-var (
-	ec2NodeClassGVK = schema.GroupVersionKind{
-		Group:   "karpenter.k8s.aws",
-		Version: "v1",
-		Kind:    "EC2NodeClass",
-	}
-	nodePoolGVK = schema.GroupVersionKind{
-		Group:   "karpenter.sh",
-		Version: "v1",
-		Kind:    "NodePool",
-	}
-)
+// API reads and writes Karpenter objects of one served API version.
+type API struct {
+	// Version is the Karpenter API version, APIVersionV1 or APIVersionV1beta1.
+	Version string
+	// NodeOverlays reports whether karpenter.sh/v1alpha1 NodeOverlays are served.
+	NodeOverlays bool
+	nodePoolGVK  schema.GroupVersionKind
+	// nodeClassGVK is the node class kind in use; nodeClassVersion lists the
+	// served kinds with their versions.
+	nodeClassGVK     schema.GroupVersionKind
+	nodeClassVersion map[NodeClassKind]string
+	render           renderer
+}
 
-// ResolveNodeClassName finds the EC2NodeClass by explicit name or label selector.
-// Returns explicit name if both provided.
-func ResolveNodeClassName(ctx context.Context, c client.Client, log logr.Logger, explicit string, selector map[string]string) (string, error) {
-	if explicit != "" && len(selector) > 0 {
-		log.Info("Both nodeClassName and nodeClassSelector set; using nodeClassName", "nodeClassName", explicit)
-	}
-	if explicit != "" {
-		obj := &unstructured.Unstructured{}
-		obj.SetGroupVersionKind(ec2NodeClassGVK)
-		if err := c.Get(ctx, client.ObjectKey{Name: explicit}, obj); err != nil {
-			return "", fmt.Errorf("ec2nodeclass %q get failed: %w", explicit, err)
-		}
-		return explicit, nil
-	}
-	if len(selector) == 0 {
-		return "", fmt.Errorf("neither nodeClassName nor nodeClassSelector provided")
-	}
-	list := &unstructured.UnstructuredList{}
-	list.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   ec2NodeClassGVK.Group,
-		Version: ec2NodeClassGVK.Version,
-		Kind:    "EC2NodeClassList",
-	})
-	if err := c.List(ctx, list, client.MatchingLabels(selector)); err != nil {
-		return "", fmt.Errorf("listing EC2NodeClass with selector %v: %w", selector, err)
-	}
-	if len(list.Items) == 0 {
-		return "", fmt.Errorf("no EC2NodeClass matched selector %v", selector)
-	}
-	if len(list.Items) > 1 {
-		names := make([]string, 0, len(list.Items))
-		for i := range list.Items {
-			names = append(names, list.Items[i].GetName())
+// AutoMode reports whether node classes are EKS Auto Mode NodeClasses.
+func (a *API) AutoMode() bool {
+	return a.nodeClassGVK.Group == AutoModeNodeClass.Group && a.nodeClassGVK.Kind == AutoModeNodeClass.Kind
+}
+
+// ownerReference returns a controller reference to owner (cluster-scoped).
+func ownerReference(owner client.Object) metav1.OwnerReference {
+	gvk := owner.GetObjectKind().GroupVersionKind()
+	// Fallback if empty (can occur with typed objects read from the cache)
+	if gvk.Empty() {
+		gvk = schema.GroupVersionKind{
+			Group:   "gpu.devplatforms.io",
+			Version: "v1alpha1",
+			Kind:    "LeftoverNodePool",
 		}
-		sort.Strings(names)
-		return "", fmt.Errorf("selector %v matched multiple EC2NodeClasses: %v", selector, names)
 	}
-	return list.Items[0].GetName(), nil
+	ctrl := true
+	block := true
+	return metav1.OwnerReference{
+		APIVersion:         gvk.GroupVersion().String(),
+		Kind:               gvk.Kind,
+		Name:               owner.GetName(),
+		UID:                owner.GetUID(),
+		Controller:         &ctrl,
+		BlockOwnerDeletion: &block,
+	}
 }
 
-// UpsertNodePool creates or updates a Karpenter NodePool with a single chosen instance type + zone.
-func UpsertNodePool(ctx context.Context, c client.Client, fieldOwner, name, nodeClassName, instanceType, zone, capacityType string, owner client.Object) error {
-	if capacityType == "" {
-		capacityType = "spot"
+// ApplyNodeClass server-side-applies an EC2NodeClass owned by owner. spec is
+// the EC2NodeClass v1 spec, rendered for the served version; subnet and
+// security group selector terms are built from the given tags.
+func (a *API) ApplyNodeClass(ctx context.Context, c client.Client, fieldOwner, name string, spec map[string]any, subnetTags, securityGroupTags map[string]string, owner client.Object) error {
+	if a.AutoMode() {
+		return fmt.Errorf("node class templates render EC2NodeClasses and cannot be used with %s", AutoModeNodeClass)
 	}
 	u := &unstructured.Unstructured{}
-	u.SetGroupVersionKind(nodePoolGVK)
+	u.SetGroupVersionKind(a.nodeClassGVK)
 	u.SetName(name)
 	u.SetLabels(map[string]string{"managed-by": "leftover"})
-
 	if owner != nil {
-		// Ensure owner GVK (cluster-scoped)
-		gvk := owner.GetObjectKind().GroupVersionKind()
-		// Fallback if empty (can occur with unstructured)
-		if gvk.Empty() {
-			gvk = schema.GroupVersionKind{
-				Group:   "gpu.devplatforms.io",
-				Version: "v1alpha1",
-				Kind:    "LeftoverNodePool",
+		u.SetOwnerReferences([]metav1.OwnerReference{ownerReference(owner)})
+	}
+
+	out := a.render.nodeClassSpec(spec)
+	out["subnetSelectorTerms"] = []any{map[string]any{"tags": stringMap(subnetTags)}}
+	out["securityGroupSelectorTerms"] = []any{map[string]any{"tags": stringMap(securityGroupTags)}}
+	u.Object["spec"] = out
+
+	return c.Patch(ctx, u, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership)
+}
+
+// DeleteOwnedNodeClass deletes the EC2NodeClass name if it is controlled by
+// owner, e.g. after a LeftoverNodePool switched back to a user-managed class.
+func (a *API) DeleteOwnedNodeClass(ctx context.Context, c client.Client, name string, owner client.Object) error {
+	return deleteOwned(ctx, c, a.nodeClassGVK, name, owner)
+}
+
+func deleteOwned(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name string, owner client.Object) error {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, u); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ref := metav1.GetControllerOf(u); ref == nil || ref.UID != owner.GetUID() {
+		return nil
+	}
+	return client.IgnoreNotFound(c.Delete(ctx, u))
+}
+
+func stringMap(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// NodePoolParams describes the NodePool for the chosen instance types and zones.
+type NodePoolParams struct {
+	Name          string
+	NodeClassName string
+	InstanceTypes []string
+	Zones         []string
+	// CapacityType is spot (default) or on-demand.
+	CapacityType string
+	// Architectures of the instance types (amd64 when empty).
+	Architectures []string
+	// Attributes, when set, are required instead of InstanceTypes (see AddAttributes).
+	Attributes map[string][]string
+	// Weight ranks the NodePool among others Karpenter may choose (0 leaves it unset).
+	Weight int32
+	// Limits caps the resources of the NodePool's nodes.
+	Limits corev1.ResourceList
+}
+
+// ApplyOptions controls how UpsertNodePool applies a NodePool.
+type ApplyOptions struct {
+	FieldOwner string
+	// Force takes ownership of fields other managers changed.
+	Force bool
+	// Adopt applies only the selection requirements to an existing NodePool
+	// (see AdoptedNodePool) instead of owning the whole NodePool.
+	Adopt bool
+}
+
+// UpsertNodePool creates or updates a Karpenter NodePool with the chosen
+// instance types and zones. A non-nil ConflictError reports managers that changed
+// the requirements since the last apply; it is also returned as the error when
+// the NodePool was not applied because of it.
+func (a *API) UpsertNodePool(ctx context.Context, c client.Client, opts ApplyOptions, p NodePoolParams, owner client.Object) (*ConflictError, error) {
+	if p.CapacityType == "" {
+		p.CapacityType = "spot"
+	}
+	if len(p.Architectures) == 0 {
+		p.Architectures = []string{"amd64"}
+	}
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(a.nodePoolGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: p.Name}, existing); err != nil {
+		if !apierrors.IsNotFound(err) || opts.Adopt {
+			return nil, fmt.Errorf("nodepool %q get failed: %w", p.Name, err)
+		}
+		existing = nil
+	}
+
+	var conflict *ConflictError
+	if existing != nil {
+		if managers := changedBy(existing, opts.FieldOwner); len(managers) > 0 {
+			conflict = &ConflictError{NodePool: p.Name, Managers: managers}
+			if !opts.Force {
+				return conflict, conflict
 			}
 		}
-		ctrl := true
-		block := true
-		u.SetOwnerReferences([]metav1.OwnerReference{
-			{
-				APIVersion:         gvk.GroupVersion().String(),
-				Kind:               gvk.Kind,
-				Name:               owner.GetName(),
-				UID:                owner.GetUID(),
-				Controller:         &ctrl,
-				BlockOwnerDeletion: &block,
-			},
-		})
-	}
-
-	u.Object["spec"] = map[string]any{
-		"template": map[string]any{
-			"spec": map[string]any{
-				"nodeClassRef": map[string]any{
-					"name":  nodeClassName,
-					"group": "karpenter.k8s.aws",
-					"kind":  "EC2NodeClass",
-				},
-				"requirements": []any{
-					map[string]any{
-						"key":      "kubernetes.io/arch",
-						"operator": string(corev1.NodeSelectorOpIn),
-						"values":   []any{"amd64"},
-					},
-					map[string]any{
-						"key":      "karpenter.sh/capacity-type",
-						"operator": string(corev1.NodeSelectorOpIn),
-						"values":   []any{capacityType},
-					},
-					map[string]any{
-						"key":      "node.kubernetes.io/instance-type",
-						"operator": string(corev1.NodeSelectorOpIn),
-						"values":   []any{instanceType},
-					},
-					map[string]any{
-						"key":      "topology.kubernetes.io/zone",
-						"operator": string(corev1.NodeSelectorOpIn),
-						"values":   []any{zone},
-					},
-				},
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(a.nodePoolGVK)
+	u.SetName(p.Name)
+	if opts.Adopt {
+		u.Object["spec"] = map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{"requirements": adoptedRequirements(existing, p, a.AutoMode())},
 			},
-		},
+		}
+	} else {
+		u.SetLabels(map[string]string{"managed-by": "leftover"})
+		if owner != nil {
+			u.SetOwnerReferences([]metav1.OwnerReference{ownerReference(owner)})
+		}
+		u.Object["spec"] = a.render.nodePoolSpec(a.nodeClassGVK, p)
 	}
 
-	return c.Patch(ctx, u, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership)
+	patchOpts := []client.PatchOption{client.FieldOwner(opts.FieldOwner)}
+	if opts.Force {
+		patchOpts = append(patchOpts, client.ForceOwnership)
+	}
+	if err := c.Patch(ctx, u, client.Apply, patchOpts...); err != nil {
+		if apierrors.IsConflict(err) && existing != nil {
+			conflict = &ConflictError{NodePool: p.Name, Managers: owners(existing, opts.FieldOwner), Err: err}
+			return conflict, conflict
+		}
+		return conflict, err
+	}
+	return conflict, nil
+}
+
+// adoptedRequirements replaces the selection requirements of an adopted
+// NodePool and keeps all others.
+func adoptedRequirements(existing *unstructured.Unstructured, p NodePoolParams, autoMode bool) []any {
+	raw, _, _ := unstructured.NestedSlice(existing.Object, requirementsPath...)
+	out := make([]any, 0, len(raw)+len(selectionKeys))
+	for _, r := range raw {
+		if !selectionKeys[requirementFrom(r).Key] {
+			out = append(out, r)
+		}
+	}
+	for _, r := range requirements(p, autoMode) {
+		if key, _ := r.(map[string]any)["key"].(string); selectionKeys[key] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// DeleteOwnedNodePool deletes the NodePool name if it is controlled by owner,
+// e.g. after a LeftoverNodePool switched to an adopted NodePool.
+func (a *API) DeleteOwnedNodePool(ctx context.Context, c client.Client, name string, owner client.Object) error {
+	return deleteOwned(ctx, c, a.nodePoolGVK, name, owner)
 }
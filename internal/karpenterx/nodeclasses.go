@@ -0,0 +1,233 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReasonNodeClassUnresolved is the preflight reason for instance types no
+// EC2NodeClass is configured or compatible for.
+const ReasonNodeClassUnresolved = "NodeClassUnresolved"
+
+// LabelCompatiblePrefix prefixes EC2NodeClass labels declaring the instance
+// families or types a class supports, e.g. compatible.gpu.devplatforms.io/p5=true.
+// A class with such labels only serves what it declares.
+const LabelCompatiblePrefix = "compatible.gpu.devplatforms.io/"
+
+// NodeClassSource names an EC2NodeClass or selects candidates by labels.
+type NodeClassSource struct {
+	Name     string
+	Selector map[string]string
+}
+
+func (s NodeClassSource) empty() bool { return s.Name == "" && len(s.Selector) == 0 }
+
+// NodeClassRoute sends instance types matching one of Patterns to Source.
+// Patterns are shell globs matched against the instance type and its family.
+type NodeClassRoute struct {
+	Patterns []string
+	Source   NodeClassSource
+}
+
+// Matches reports whether instanceType matches one of the route's patterns.
+func (r NodeClassRoute) Matches(instanceType string) bool {
+	family, _, _ := strings.Cut(instanceType, ".")
+	for _, p := range r.Patterns {
+		if ok, _ := path.Match(p, instanceType); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, family); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeClasses resolves the EC2NodeClass of each candidate instance type: the
+// first route matching the type decides, otherwise the fallback source.
+type NodeClasses struct {
+	routes   []NodeClassRoute
+	fallback NodeClassSource
+	// all is sorted by name.
+	all    []*NodeClassStatus
+	byName map[string]*NodeClassStatus
+}
+
+// LoadNodeClasses reads all EC2NodeClasses and checks that every named class
+// exists and every selector matches at least one class.
+func (a *API) LoadNodeClasses(ctx context.Context, c client.Client, routes []NodeClassRoute, fallback NodeClassSource) (*NodeClasses, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(a.nodeClassGVK.GroupVersion().WithKind("EC2NodeClassList"))
+	if err := c.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("listing EC2NodeClasses: %w", err)
+	}
+	n := &NodeClasses{routes: routes, fallback: fallback, byName: map[string]*NodeClassStatus{}}
+	for i := range list.Items {
+		st := nodeClassStatus(&list.Items[i])
+		n.all = append(n.all, st)
+		n.byName[st.Name] = st
+	}
+	sort.Slice(n.all, func(i, j int) bool { return n.all[i].Name < n.all[j].Name })
+
+	sources := []NodeClassSource{fallback}
+	for _, r := range routes {
+		sources = append(sources, r.Source)
+	}
+	for _, src := range sources {
+		switch {
+		case src.Name != "":
+			if n.byName[src.Name] == nil {
+				return nil, fmt.Errorf("ec2nodeclass %q not found", src.Name)
+			}
+		case len(src.Selector) > 0:
+			if len(n.selected(src.Selector)) == 0 {
+				return nil, fmt.Errorf("no EC2NodeClass matched selector %v", src.Selector)
+			}
+		}
+	}
+	return n, nil
+}
+
+// selected returns the classes matching selector, sorted by name.
+func (n *NodeClasses) selected(selector map[string]string) []*NodeClassStatus {
+	sel := labels.SelectorFromSet(selector)
+	var out []*NodeClassStatus
+	for _, st := range n.all {
+		if sel.Matches(labels.Set(st.Labels)) {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// Only returns the class every instance type resolves to, or nil when the
+// class depends on the instance type.
+func (n *NodeClasses) Only() *NodeClassStatus {
+	if len(n.routes) > 0 {
+		return nil
+	}
+	if n.fallback.Name != "" {
+		return n.byName[n.fallback.Name]
+	}
+	if matched := n.selected(n.fallback.Selector); len(n.fallback.Selector) > 0 && len(matched) == 1 {
+		return matched[0]
+	}
+	return nil
+}
+
+// Primary returns the first class the fallback or the routes, in that order,
+// can resolve to, or nil when there is none.
+func (n *NodeClasses) Primary() *NodeClassStatus {
+	sources := []NodeClassSource{n.fallback}
+	for _, r := range n.routes {
+		sources = append(sources, r.Source)
+	}
+	for _, src := range sources {
+		if src.Name != "" {
+			return n.byName[src.Name]
+		}
+		if len(src.Selector) > 0 {
+			if matched := n.selected(src.Selector); len(matched) > 0 {
+				return matched[0]
+			}
+		}
+	}
+	return nil
+}
+
+// For resolves the class for instanceType. Among several classes matching a
+// selector, one declaring the instance type wins over one declaring its
+// family, which wins over one declaring nothing; classes declaring other
+// families or types are skipped.
+func (n *NodeClasses) For(instanceType string) (*NodeClassStatus, *PreflightError) {
+	src := n.fallback
+	for _, r := range n.routes {
+		if r.Matches(instanceType) {
+			src = r.Source
+			break
+		}
+	}
+	if src.empty() {
+		return nil, &PreflightError{
+			Reason:  ReasonNodeClassUnresolved,
+			Message: fmt.Sprintf("no nodeClassMappings entry matches %s", instanceType),
+		}
+	}
+	if src.Name != "" {
+		return n.byName[src.Name], nil
+	}
+
+	family, _, _ := strings.Cut(instanceType, ".")
+	var best []*NodeClassStatus
+	bestRank := 0
+	for _, st := range n.selected(src.Selector) {
+		rank := compatibility(st.Labels, instanceType, family)
+		switch {
+		case rank == 0 || rank < bestRank:
+		case rank > bestRank:
+			best, bestRank = []*NodeClassStatus{st}, rank
+		default:
+			best = append(best, st)
+		}
+	}
+	switch len(best) {
+	case 1:
+		return best[0], nil
+	case 0:
+		return nil, &PreflightError{
+			Reason:  ReasonNodeClassUnresolved,
+			Message: fmt.Sprintf("no EC2NodeClass matching selector %v is compatible with %s", src.Selector, instanceType),
+		}
+	default:
+		names := make([]string, 0, len(best))
+		for _, st := range best {
+			names = append(names, st.Name)
+		}
+		return nil, &PreflightError{
+			Reason: ReasonNodeClassUnresolved,
+			Message: fmt.Sprintf("selector %v matches several EC2NodeClasses for %s: %v; label one %s%s=true",
+				src.Selector, instanceType, names, LabelCompatiblePrefix, family),
+		}
+	}
+}
+
+// compatibility ranks how specifically a class's labels declare support for
+// an instance type: 3 for the type, 2 for its family, 1 for a class declaring
+// nothing and 0 for a class declaring only other families or types.
+func compatibility(classLabels map[string]string, instanceType, family string) int {
+	switch {
+	case classLabels[LabelCompatiblePrefix+instanceType] == "true":
+		return 3
+	case classLabels[LabelCompatiblePrefix+family] == "true":
+		return 2
+	}
+	for k := range classLabels {
+		if strings.HasPrefix(k, LabelCompatiblePrefix) {
+			return 0
+		}
+	}
+	return 1
+}
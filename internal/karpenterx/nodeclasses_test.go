@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import "testing"
+
+func classes(routes []NodeClassRoute, fallback NodeClassSource, all ...*NodeClassStatus) *NodeClasses {
+	n := &NodeClasses{routes: routes, fallback: fallback, all: all, byName: map[string]*NodeClassStatus{}}
+	for _, st := range all {
+		n.byName[st.Name] = st
+	}
+	return n
+}
+
+func TestNodeClassesFor(t *testing.T) {
+	gpu := map[string]string{"team": "ml"}
+	generic := &NodeClassStatus{Name: "generic", Labels: gpu}
+	efa := &NodeClassStatus{Name: "efa", Labels: map[string]string{"team": "ml", LabelCompatiblePrefix + "p5": "true"}}
+	neuron := &NodeClassStatus{Name: "neuron", Labels: map[string]string{"team": "ml", LabelCompatiblePrefix + "inf2": "true"}}
+	big := &NodeClassStatus{Name: "big", Labels: map[string]string{"team": "ml", LabelCompatiblePrefix + "g6e.48xlarge": "true"}}
+	g6e := &NodeClassStatus{Name: "g6e", Labels: map[string]string{"team": "ml", LabelCompatiblePrefix + "g6e": "true"}}
+	bottlerocket := &NodeClassStatus{Name: "bottlerocket"}
+
+	bySelector := classes(nil, NodeClassSource{Selector: gpu}, big, efa, g6e, generic, neuron)
+	routed := classes([]NodeClassRoute{
+		{Patterns: []string{"g5*", "g6.2xlarge"}, Source: NodeClassSource{Name: "bottlerocket"}},
+		{Patterns: []string{"inf*"}, Source: NodeClassSource{Selector: gpu}},
+	}, NodeClassSource{}, bottlerocket, generic, neuron)
+	ambiguous := classes(nil, NodeClassSource{Selector: gpu}, efa, generic, &NodeClassStatus{Name: "generic2", Labels: gpu})
+
+	cases := []struct {
+		name    string
+		classes *NodeClasses
+		typ     string
+		want    string
+	}{
+		{"family label", bySelector, "p5.48xlarge", "efa"},
+		{"type label beats family label", bySelector, "g6e.48xlarge", "big"},
+		{"family label for other sizes", bySelector, "g6e.12xlarge", "g6e"},
+		{"undeclared class as default", bySelector, "g5.xlarge", "generic"},
+		{"route by family glob", routed, "g5g.xlarge", "bottlerocket"},
+		{"route by type", routed, "g6.2xlarge", "bottlerocket"},
+		{"routed selector", routed, "inf2.xlarge", "neuron"},
+		{"no route", routed, "p5.48xlarge", ""},
+		{"several undeclared classes", ambiguous, "g5.xlarge", ""},
+		{"declared class among several", ambiguous, "p5.48xlarge", "efa"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, perr := tc.classes.For(tc.typ)
+			if tc.want == "" {
+				if perr == nil || perr.Reason != ReasonNodeClassUnresolved {
+					t.Fatalf("For(%s) = %v, %v; want %s", tc.typ, got, perr, ReasonNodeClassUnresolved)
+				}
+				return
+			}
+			if perr != nil {
+				t.Fatalf("For(%s) failed: %v", tc.typ, perr)
+			}
+			if got.Name != tc.want {
+				t.Errorf("For(%s) = %s, want %s", tc.typ, got.Name, tc.want)
+			}
+		})
+	}
+}
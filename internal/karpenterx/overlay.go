@@ -0,0 +1,91 @@
+package karpenterx
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var nodeOverlayGVK = schema.GroupVersionKind{Group: "karpenter.sh", Version: "v1alpha1", Kind: "NodeOverlay"}
+
+// PriceHint is the price Karpenter should assume for an offering.
+type PriceHint struct {
+	Offering
+	CapacityType string
+	PriceUSD     float64
+}
+
+// NodeOverlayName is the NodeOverlay carrying the hint for o in nodePool.
+func NodeOverlayName(nodePool string, o Offering) string {
+	return fmt.Sprintf("%s-%s-%s", nodePool, o.InstanceType, o.Zone)
+}
+
+// SyncNodeOverlays applies one NodeOverlay per hint, scoped to nodePool, and
+// deletes the NodeOverlays owner created for hints no longer given. With no
+// hints it removes all of owner's NodeOverlays.
+func (a *API) SyncNodeOverlays(ctx context.Context, c client.Client, fieldOwner, nodePool string, hints []PriceHint, owner client.Object) error {
+	if !a.NodeOverlays {
+		if len(hints) > 0 {
+			return ErrNodeOverlayUnsupported
+		}
+		return nil
+	}
+	keep := make(map[string]bool, len(hints))
+	for _, h := range hints {
+		u := nodeOverlay(nodePool, h)
+		u.SetOwnerReferences([]metav1.OwnerReference{ownerReference(owner)})
+		if err := c.Patch(ctx, u, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+			return fmt.Errorf("applying NodeOverlay %q: %w", u.GetName(), err)
+		}
+		keep[u.GetName()] = true
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(nodeOverlayGVK.GroupVersion().WithKind(nodeOverlayGVK.Kind + "List"))
+	if err := c.List(ctx, list, client.MatchingLabels{"managed-by": "leftover"}); err != nil {
+		return fmt.Errorf("listing NodeOverlays: %w", err)
+	}
+	for i := range list.Items {
+		u := &list.Items[i]
+		if keep[u.GetName()] {
+			continue
+		}
+		if ref := metav1.GetControllerOf(u); ref == nil || ref.UID != owner.GetUID() {
+			continue
+		}
+		if err := c.Delete(ctx, u); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting NodeOverlay %q: %w", u.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// nodeOverlay renders the NodeOverlay of h. The nodepool requirement keeps
+// the price from applying to other NodePools.
+func nodeOverlay(nodePool string, h PriceHint) *unstructured.Unstructured {
+	reqs := []corev1.NodeSelectorRequirement{
+		{Key: LabelNodePool, Operator: corev1.NodeSelectorOpIn, Values: []string{nodePool}},
+		{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{h.CapacityType}},
+		{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{h.InstanceType}},
+		{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{h.Zone}},
+	}
+	out := make([]any, 0, len(reqs))
+	for _, r := range reqs {
+		out = append(out, map[string]any{"key": r.Key, "operator": string(r.Operator), "values": []any{r.Values[0]}})
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(nodeOverlayGVK)
+	u.SetName(NodeOverlayName(nodePool, h.Offering))
+	u.SetLabels(map[string]string{"managed-by": "leftover"})
+	u.Object["spec"] = map[string]any{
+		"requirements": out,
+		"price":        fmt.Sprintf("%.4f", h.PriceUSD),
+	}
+	return u
+}
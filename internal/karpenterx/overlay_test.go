@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestNodeOverlay(t *testing.T) {
+	h := PriceHint{Offering: Offering{InstanceType: "g6.xlarge", Zone: "us-east-1a"}, CapacityType: "spot", PriceUSD: 0.41237}
+	u := nodeOverlay("leftover-a", h)
+	if u.GetName() != "leftover-a-g6.xlarge-us-east-1a" {
+		t.Errorf("name = %q", u.GetName())
+	}
+	want := map[string]any{
+		"requirements": []any{
+			map[string]any{"key": "karpenter.sh/nodepool", "operator": "In", "values": []any{"leftover-a"}},
+			map[string]any{"key": "karpenter.sh/capacity-type", "operator": "In", "values": []any{"spot"}},
+			map[string]any{"key": "node.kubernetes.io/instance-type", "operator": "In", "values": []any{"g6.xlarge"}},
+			map[string]any{"key": "topology.kubernetes.io/zone", "operator": "In", "values": []any{"us-east-1a"}},
+		},
+		"price": "0.4124",
+	}
+	if !reflect.DeepEqual(u.Object["spec"], want) {
+		t.Errorf("spec = %v, want %v", u.Object["spec"], want)
+	}
+}
+
+func TestDetectorNodeOverlays(t *testing.T) {
+	base := []*metav1.APIResourceList{served("karpenter.sh/v1", "nodepools"), served("karpenter.k8s.aws/v1", "ec2nodeclasses")}
+	for _, tc := range []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		want      bool
+	}{
+		{"served", append(base, served("karpenter.sh/v1alpha1", "nodeoverlays")), true},
+		{"not served", base, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			disc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tc.resources}}
+			api, err := NewDetector(disc).API()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if api.NodeOverlays != tc.want {
+				t.Errorf("NodeOverlays = %v, want %v", api.NodeOverlays, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequirementsSkipUnconstrained(t *testing.T) {
+	p := NodePoolParams{InstanceTypes: []string{"g6.xlarge", "g5.xlarge"}, CapacityType: "spot", Architectures: []string{"amd64"}}
+	for _, r := range requirements(p, false) {
+		if key := r.(map[string]any)["key"]; key == "topology.kubernetes.io/zone" {
+			t.Errorf("zone requirement rendered without zones: %v", r)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReasonNodePoolRequirementMismatch is the preflight reason for instance types
+// an adopted NodePool's own requirements exclude.
+const ReasonNodePoolRequirementMismatch = "NodePoolRequirementMismatch"
+
+// selectionKeys are the requirement keys Leftover maintains on adopted NodePools.
+var selectionKeys = map[string]bool{
+	"karpenter.sh/capacity-type":   true,
+	corev1.LabelInstanceTypeStable: true,
+	corev1.LabelTopologyZone:       true,
+}
+
+// requirementsPath holds the selection. Karpenter declares requirements an
+// atomic list, so server-side apply tracks its ownership as a whole.
+var requirementsPath = []string{"spec", "template", "spec", "requirements"}
+
+// AdoptedNodePool is an existing NodePool whose selection Leftover maintains.
+type AdoptedNodePool struct {
+	Name string
+	// NodeClassName is the node class the NodePool references.
+	NodeClassName string
+	// Requirements are the NodePool's requirements on keys Leftover does not own.
+	Requirements []corev1.NodeSelectorRequirement
+}
+
+// GetAdoptedNodePool reads the NodePool name for adoption.
+func (a *API) GetAdoptedNodePool(ctx context.Context, c client.Client, name string) (*AdoptedNodePool, error) {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(a.nodePoolGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, u); err != nil {
+		return nil, fmt.Errorf("nodepool %q get failed: %w", name, err)
+	}
+	p := &AdoptedNodePool{Name: name}
+	p.NodeClassName, _, _ = unstructured.NestedString(u.Object, "spec", "template", "spec", "nodeClassRef", "name")
+	if p.NodeClassName == "" {
+		return nil, fmt.Errorf("nodepool %q references no node class", name)
+	}
+	raw, _, _ := unstructured.NestedSlice(u.Object, requirementsPath...)
+	for _, r := range raw {
+		req := requirementFrom(r)
+		if req.Key != "" && !selectionKeys[req.Key] {
+			p.Requirements = append(p.Requirements, req)
+		}
+	}
+	return p, nil
+}
+
+// Check fails when the NodePool's own requirements exclude the instance type
+// described by labels (see InstanceLabels).
+func (p *AdoptedNodePool) Check(labels map[string]string) *PreflightError {
+	if requirementsMatch(p.Requirements, labels, func(string) bool { return true }) {
+		return nil
+	}
+	return &PreflightError{
+		Reason:  ReasonNodePoolRequirementMismatch,
+		Message: fmt.Sprintf("NodePool %q requirements exclude %s", p.Name, labels[corev1.LabelInstanceTypeStable]),
+	}
+}
+
+// requirementFrom converts an unstructured NodeSelectorRequirement.
+func requirementFrom(raw any) corev1.NodeSelectorRequirement {
+	req := corev1.NodeSelectorRequirement{}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return req
+	}
+	req.Key, _ = m["key"].(string)
+	op, _ := m["operator"].(string)
+	req.Operator = corev1.NodeSelectorOperator(op)
+	vals, _ := m["values"].([]any)
+	for _, v := range vals {
+		if s, ok := v.(string); ok {
+			req.Values = append(req.Values, s)
+		}
+	}
+	return req
+}
+
+// ConflictError reports field managers other than Leftover that changed the
+// selection of a NodePool.
+type ConflictError struct {
+	NodePool string
+	Managers []string
+	// Err is the apply error when the API server refused the apply.
+	Err error
+}
+
+func (e *ConflictError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("applying NodePool %q conflicts with %s: %v", e.NodePool, managerList(e.Managers), e.Err)
+	}
+	return fmt.Sprintf("NodePool %q requirements were changed by %s", e.NodePool, managerList(e.Managers))
+}
+
+func (e *ConflictError) Unwrap() error { return e.Err }
+
+func managerList(managers []string) string {
+	if len(managers) == 0 {
+		return "another field manager"
+	}
+	return strings.Join(managers, ", ")
+}
+
+// changedBy returns the managers other than fieldOwner that took ownership of
+// the requirements since fieldOwner last applied them. A NodePool fieldOwner
+// never applied (e.g. before adoption) has no conflicts.
+func changedBy(obj *unstructured.Unstructured, fieldOwner string) []string {
+	applied := false
+	var others []string
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Subresource != "" {
+			continue
+		}
+		owns := ownsPath(mf.FieldsV1, requirementsPath)
+		if mf.Manager == fieldOwner {
+			if owns {
+				// Ownership is shared while the values are what we applied.
+				return nil
+			}
+			applied = true
+			continue
+		}
+		if owns {
+			others = append(others, mf.Manager)
+		}
+	}
+	if !applied {
+		return nil
+	}
+	return uniqueSorted(others)
+}
+
+// owners returns the managers other than fieldOwner owning the requirements.
+func owners(obj *unstructured.Unstructured, fieldOwner string) []string {
+	var out []string
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Subresource == "" && mf.Manager != fieldOwner && ownsPath(mf.FieldsV1, requirementsPath) {
+			out = append(out, mf.Manager)
+		}
+	}
+	return uniqueSorted(out)
+}
+
+// ownsPath reports whether the managed field set includes path.
+func ownsPath(fields *metav1.FieldsV1, path []string) bool {
+	if fields == nil {
+		return false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(fields.Raw, &m); err != nil {
+		return false
+	}
+	for _, p := range path {
+		next, ok := m["f:"+p].(map[string]any)
+		if !ok {
+			return false
+		}
+		m = next
+	}
+	return true
+}
+
+func uniqueSorted(in []string) []string {
+	sort.Strings(in)
+	out := in[:0]
+	for i, s := range in {
+		if i == 0 || s != in[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const requirementsFields = `{"f:spec":{"f:template":{"f:spec":{"f:requirements":{}}}}}`
+
+func managed(manager, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{Manager: manager, Operation: metav1.ManagedFieldsOperationApply, FieldsV1: &metav1.FieldsV1{Raw: []byte(fields)}}
+}
+
+func TestChangedBy(t *testing.T) {
+	other := `{"f:spec":{"f:template":{"f:spec":{"f:nodeClassRef":{}}}}}`
+	cases := []struct {
+		name    string
+		entries []metav1.ManagedFieldsEntry
+		want    []string
+	}{
+		{"owned by us", []metav1.ManagedFieldsEntry{managed("leftover", requirementsFields), managed("argocd", other)}, nil},
+		{"shared with identical values", []metav1.ManagedFieldsEntry{managed("leftover", requirementsFields), managed("argocd", requirementsFields)}, nil},
+		{"taken over", []metav1.ManagedFieldsEntry{managed("leftover", `{"f:metadata":{}}`), managed("kubectl-edit", requirementsFields)}, []string{"kubectl-edit"}},
+		{"never applied", []metav1.ManagedFieldsEntry{managed("argocd", requirementsFields)}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: map[string]any{}}
+			u.SetManagedFields(tc.entries)
+			if got := changedBy(u, "leftover"); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("changedBy = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAdoptedRequirements(t *testing.T) {
+	existing := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"requirements": []any{
+			map[string]any{"key": "kubernetes.io/arch", "operator": "In", "values": []any{"amd64"}},
+			map[string]any{"key": "node.kubernetes.io/instance-type", "operator": "In", "values": []any{"g5.xlarge"}},
+		}}}},
+	}}
+	p := NodePoolParams{InstanceTypes: []string{"g6.xlarge"}, Zones: []string{"us-east-1b"}, CapacityType: "spot", Architectures: []string{"amd64"}}
+	var keys []string
+	for _, r := range adoptedRequirements(existing, p, false) {
+		req := requirementFrom(r)
+		keys = append(keys, req.Key+"="+req.Values[0])
+	}
+	want := []string{
+		"kubernetes.io/arch=amd64",
+		"karpenter.sh/capacity-type=spot",
+		"node.kubernetes.io/instance-type=g6.xlarge",
+		"topology.kubernetes.io/zone=us-east-1b",
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("adoptedRequirements = %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+)
+
+// Preflight failure reasons, used as Ready condition reasons.
+const (
+	ReasonNodeClassNotReady       = "NodeClassNotReady"
+	ReasonAMIArchitectureMismatch = "AMIArchitectureMismatch"
+	ReasonAMIAcceleratorMismatch  = "AMIAcceleratorMismatch"
+	ReasonSubnetZoneUncovered     = "SubnetZoneUncovered"
+)
+
+// PreflightError explains why a node class cannot serve a selection.
+type PreflightError struct {
+	Reason  string
+	Message string
+}
+
+func (e *PreflightError) Error() string { return e.Message }
+
+// NodeClassStatus is the AMI settings of an EC2NodeClass and what Karpenter
+// resolved for it.
+type NodeClassStatus struct {
+	Name   string
+	Labels map[string]string
+	// AMIFamily is spec.amiFamily (e.g. AL2023, Windows2022, Custom); may be empty.
+	AMIFamily string
+	// AMIAliases are the spec.amiSelectorTerms aliases (e.g. "al2023@latest").
+	AMIAliases []string
+	// Ready is nil when the class reports no Ready condition (older Karpenter).
+	Ready        *bool
+	ReadyMessage string
+	AMIs         []ResolvedAMI
+	// Zones covered by the resolved subnets; empty when unknown.
+	Zones map[string]bool
+}
+
+// ResolvedAMI is one entry of EC2NodeClass status.amis.
+type ResolvedAMI struct {
+	ID           string
+	Requirements []corev1.NodeSelectorRequirement
+}
+
+func nodeClassStatus(obj *unstructured.Unstructured) *NodeClassStatus {
+	st := &NodeClassStatus{Name: obj.GetName(), Labels: obj.GetLabels(), Zones: map[string]bool{}}
+	st.AMIFamily, _, _ = unstructured.NestedString(obj.Object, "spec", "amiFamily")
+	terms, _, _ := unstructured.NestedSlice(obj.Object, "spec", "amiSelectorTerms")
+	for _, t := range terms {
+		if m, ok := t.(map[string]any); ok {
+			if alias, ok := m["alias"].(string); ok && alias != "" {
+				st.AMIAliases = append(st.AMIAliases, alias)
+			}
+		}
+	}
+
+	conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conds {
+		m, ok := raw.(map[string]any)
+		if !ok || m["type"] != "Ready" {
+			continue
+		}
+		ready := m["status"] == "True"
+		st.Ready = &ready
+		st.ReadyMessage, _ = m["message"].(string)
+	}
+
+	amis, _, _ := unstructured.NestedSlice(obj.Object, "status", "amis")
+	for _, raw := range amis {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		ami := ResolvedAMI{}
+		ami.ID, _ = m["id"].(string)
+		reqs, _ := m["requirements"].([]any)
+		for _, r := range reqs {
+			ami.Requirements = append(ami.Requirements, requirementFrom(r))
+		}
+		st.AMIs = append(st.AMIs, ami)
+	}
+
+	subnets, _, _ := unstructured.NestedSlice(obj.Object, "status", "subnets")
+	for _, raw := range subnets {
+		if m, ok := raw.(map[string]any); ok {
+			if z, ok := m["zone"].(string); ok && z != "" {
+				st.Zones[z] = true
+			}
+		}
+	}
+	return st
+}
+
+// CheckReady fails when the class reports Ready=False. Classes without a Ready
+// condition (Karpenter before v1) pass.
+func (s *NodeClassStatus) CheckReady() *PreflightError {
+	if s.Ready == nil || *s.Ready {
+		return nil
+	}
+	msg := fmt.Sprintf("EC2NodeClass %q is not ready", s.Name)
+	if s.ReadyMessage != "" {
+		msg += ": " + s.ReadyMessage
+	}
+	return &PreflightError{Reason: ReasonNodeClassNotReady, Message: msg}
+}
+
+// Check verifies that the class can launch instanceType (described by labels,
+// see InstanceLabels) in zone: some resolved AMI must accept the instance's
+// architecture and accelerators, and a resolved subnet must be in zone. Checks
+// whose status data is missing pass.
+func (s *NodeClassStatus) Check(labels map[string]string, zone string) *PreflightError {
+	it := labels[corev1.LabelInstanceTypeStable]
+	if len(s.AMIs) > 0 && !anyAMI(s.AMIs, labels, func(string) bool { return true }) {
+		if anyAMI(s.AMIs, labels, isArchKey) {
+			// An AMI fits the architecture but none the accelerators.
+			return &PreflightError{
+				Reason:  ReasonAMIAcceleratorMismatch,
+				Message: fmt.Sprintf("EC2NodeClass %q has no AMI with drivers for %s (%s GPUs)", s.Name, it, labels[LabelInstanceGPUManufacturer]),
+			}
+		}
+		return &PreflightError{
+			Reason:  ReasonAMIArchitectureMismatch,
+			Message: fmt.Sprintf("EC2NodeClass %q has no AMI for architecture %s of %s", s.Name, labels[corev1.LabelArchStable], it),
+		}
+	}
+	if len(s.Zones) > 0 && !s.Zones[zone] {
+		return &PreflightError{
+			Reason:  ReasonSubnetZoneUncovered,
+			Message: fmt.Sprintf("EC2NodeClass %q has no subnet in %s", s.Name, zone),
+		}
+	}
+	return nil
+}
+
+// anyAMI reports whether some AMI satisfies its requirements selected by include.
+func anyAMI(amis []ResolvedAMI, labels map[string]string, include func(string) bool) bool {
+	for _, ami := range amis {
+		if requirementsMatch(ami.Requirements, labels, include) {
+			return true
+		}
+	}
+	return false
+}
+
+func isArchKey(key string) bool { return key == corev1.LabelArchStable }
+
+// requirementsMatch evaluates the requirements selected by include against
+// labels. Requirements on labels we do not know pass.
+func requirementsMatch(reqs []corev1.NodeSelectorRequirement, labels map[string]string, include func(string) bool) bool {
+	for _, r := range reqs {
+		if !include(r.Key) {
+			continue
+		}
+		v, has := labels[r.Key]
+		if !has && !knownLabels[r.Key] {
+			continue
+		}
+		switch r.Operator {
+		case corev1.NodeSelectorOpIn:
+			if !has || !contains(r.Values, v) {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if has && contains(r.Values, v) {
+				return false
+			}
+		case corev1.NodeSelectorOpExists:
+			if !has {
+				return false
+			}
+		case corev1.NodeSelectorOpDoesNotExist:
+			if has {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Karpenter well-known instance labels.
+const (
+	LabelInstanceFamily          = "karpenter.k8s.aws/instance-family"
+	LabelInstanceGeneration      = "karpenter.k8s.aws/instance-generation"
+	LabelInstanceGPUCount        = "karpenter.k8s.aws/instance-gpu-count"
+	LabelInstanceGPUName         = "karpenter.k8s.aws/instance-gpu-name"
+	LabelInstanceGPUManufacturer = "karpenter.k8s.aws/instance-gpu-manufacturer"
+	LabelInstanceGPUMemory       = "karpenter.k8s.aws/instance-gpu-memory"
+	LabelInstanceAcceleratorName = "karpenter.k8s.aws/instance-accelerator-name"
+	LabelInstanceAcceleratorCnt  = "karpenter.k8s.aws/instance-accelerator-count"
+)
+
+// knownLabels are the keys InstanceLabels can produce; a missing known label
+// means the instance does not have it (e.g. no accelerators).
+var knownLabels = map[string]bool{
+	corev1.LabelArchStable:         true,
+	corev1.LabelInstanceTypeStable: true,
+	LabelInstanceFamily:            true,
+	LabelInstanceGeneration:        true,
+	LabelInstanceGPUCount:          true,
+	LabelInstanceGPUName:           true,
+	LabelInstanceGPUManufacturer:   true,
+	LabelInstanceGPUMemory:         true,
+	LabelInstanceAcceleratorName:   true,
+	LabelInstanceAcceleratorCnt:    true,
+}
+
+// InstanceLabels returns the Karpenter labels a node of the given type would
+// carry, as far as they can be derived from DescribeInstanceTypes.
+func InstanceLabels(m awsx.InstanceMeta) map[string]string {
+	labels := map[string]string{corev1.LabelInstanceTypeStable: m.Type}
+	if len(m.Architectures) > 0 {
+		labels[corev1.LabelArchStable] = m.Architectures[0]
+	}
+	family, size, _ := strings.Cut(m.Type, ".")
+	if size != "" {
+		labels[LabelInstanceFamily] = family
+		if gen := generation(family); gen != "" {
+			labels[LabelInstanceGeneration] = gen
+		}
+	}
+	if m.GPUCount > 0 {
+		labels[LabelInstanceGPUCount] = strconv.Itoa(int(m.GPUCount))
+		labels[LabelInstanceGPUName] = strings.ToLower(m.GPUName)
+		labels[LabelInstanceGPUManufacturer] = strings.ToLower(m.GPUManufacturer)
+		// Karpenter reports the memory of one GPU.
+		labels[LabelInstanceGPUMemory] = strconv.Itoa(int(m.GPUMemMiB / m.GPUCount))
+	}
+	return labels
+}
+
+// generation extracts the generation digit(s) of an instance family, e.g. "5" for g5g.
+func generation(family string) string {
+	start := strings.IndexAny(family, "0123456789")
+	if start < 0 {
+		return ""
+	}
+	end := start
+	for end < len(family) && family[end] >= '0' && family[end] <= '9' {
+		end++
+	}
+	return family[start:end]
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/devplatformsolutions/leftover/internal/awsx"
+)
+
+func req(key string, op corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorRequirement {
+	return corev1.NodeSelectorRequirement{Key: key, Operator: op, Values: values}
+}
+
+// Karpenter resolves AL2023 into a standard and an NVIDIA variant per architecture.
+var al2023 = []ResolvedAMI{
+	{ID: "ami-std", Requirements: []corev1.NodeSelectorRequirement{
+		req(corev1.LabelArchStable, corev1.NodeSelectorOpIn, "amd64"),
+		req(LabelInstanceGPUCount, corev1.NodeSelectorOpDoesNotExist),
+	}},
+	{ID: "ami-nvidia", Requirements: []corev1.NodeSelectorRequirement{
+		req(corev1.LabelArchStable, corev1.NodeSelectorOpIn, "amd64"),
+		req(LabelInstanceGPUCount, corev1.NodeSelectorOpExists),
+	}},
+}
+
+func TestPreflightCheck(t *testing.T) {
+	g5 := InstanceLabels(awsx.InstanceMeta{Type: "g5.xlarge", GPUCount: 1, GPUMemMiB: 24576, GPUName: "A10G", GPUManufacturer: "NVIDIA", Architectures: []string{"amd64"}})
+	g5g := InstanceLabels(awsx.InstanceMeta{Type: "g5g.xlarge", GPUCount: 1, GPUName: "T4g", GPUManufacturer: "NVIDIA", Architectures: []string{"arm64"}})
+
+	cases := []struct {
+		name   string
+		amis   []ResolvedAMI
+		labels map[string]string
+		zone   string
+		want   string
+	}{
+		{"accelerated AMI", al2023, g5, "us-east-1a", ""},
+		{"arm64 without AMI", al2023, g5g, "us-east-1a", ReasonAMIArchitectureMismatch},
+		{"standard AMI only", al2023[:1], g5, "us-east-1a", ReasonAMIAcceleratorMismatch},
+		{"zone without subnet", al2023, g5, "us-east-1c", ReasonSubnetZoneUncovered},
+		{"unknown AMIs", nil, g5g, "us-east-1b", ""},
+	}
+	for _, tc := range cases {
+		st := &NodeClassStatus{Name: "gpu", AMIs: tc.amis, Zones: map[string]bool{"us-east-1a": true, "us-east-1b": true}}
+		got := ""
+		if perr := st.Check(tc.labels, tc.zone); perr != nil {
+			got = perr.Reason
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestInstanceLabels(t *testing.T) {
+	l := InstanceLabels(awsx.InstanceMeta{Type: "p4d.24xlarge", GPUCount: 8, GPUMemMiB: 327680, GPUName: "A100", GPUManufacturer: "NVIDIA", Architectures: []string{"amd64"}})
+	want := map[string]string{
+		LabelInstanceFamily:     "p4d",
+		LabelInstanceGeneration: "4",
+		LabelInstanceGPUName:    "a100",
+		LabelInstanceGPUMemory:  "40960",
+		LabelInstanceGPUCount:   "8",
+	}
+	for k, v := range want {
+		if l[k] != v {
+			t.Errorf("%s: got %q, want %q", k, l[k], v)
+		}
+	}
+}
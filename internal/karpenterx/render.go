@@ -0,0 +1,195 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// renderer emits object specs in the field shapes of one Karpenter API version.
+type renderer interface {
+	// nodePoolSpec renders a NodePool referencing a node class of kind ref.
+	nodePoolSpec(ref schema.GroupVersionKind, p NodePoolParams) map[string]any
+	// nodeClassSpec converts an EC2NodeClass v1 spec.
+	nodeClassSpec(spec map[string]any) map[string]any
+}
+
+type v1Renderer struct{}
+
+func (v1Renderer) nodePoolSpec(ref schema.GroupVersionKind, p NodePoolParams) map[string]any {
+	spec := map[string]any{
+		"template": template(ref, p, map[string]any{
+			"name":  p.NodeClassName,
+			"group": ref.Group,
+			"kind":  ref.Kind,
+		}),
+	}
+	weightAndLimits(spec, p)
+	return spec
+}
+
+func (v1Renderer) nodeClassSpec(spec map[string]any) map[string]any {
+	out := make(map[string]any, len(spec)+2)
+	for k, v := range spec {
+		out[k] = v
+	}
+	return out
+}
+
+type v1beta1Renderer struct{}
+
+func (v1beta1Renderer) nodePoolSpec(ref schema.GroupVersionKind, p NodePoolParams) map[string]any {
+	spec := map[string]any{
+		"template": template(ref, p, map[string]any{
+			"name":       p.NodeClassName,
+			"apiVersion": ref.GroupVersion().String(),
+			"kind":       ref.Kind,
+		}),
+	}
+	weightAndLimits(spec, p)
+	return spec
+}
+
+// weightAndLimits sets spec.weight and spec.limits, which both API versions
+// share, when p has them.
+func weightAndLimits(spec map[string]any, p NodePoolParams) {
+	if p.Weight > 0 {
+		spec["weight"] = int64(p.Weight)
+	}
+	if len(p.Limits) > 0 {
+		limits := make(map[string]any, len(p.Limits))
+		for name, q := range p.Limits {
+			limits[string(name)] = q.String()
+		}
+		spec["limits"] = limits
+	}
+}
+
+// v1beta1AMIFamilies maps v1 AMI alias families to v1beta1 amiFamily values.
+var v1beta1AMIFamilies = map[string]string{
+	"al2":          "AL2",
+	"al2023":       "AL2023",
+	"bottlerocket": "Bottlerocket",
+	"windows2019":  "Windows2019",
+	"windows2022":  "Windows2022",
+}
+
+// nodeClassSpec replaces AMI alias terms, which v1beta1 lacks, by the alias'
+// amiFamily; v1beta1 then selects the family's latest recommended AMIs.
+func (v1beta1Renderer) nodeClassSpec(spec map[string]any) map[string]any {
+	out := make(map[string]any, len(spec)+2)
+	for k, v := range spec {
+		out[k] = v
+	}
+	terms, _ := spec["amiSelectorTerms"].([]any)
+	kept := make([]any, 0, len(terms))
+	for _, t := range terms {
+		m, _ := t.(map[string]any)
+		alias, _ := m["alias"].(string)
+		if alias == "" {
+			kept = append(kept, t)
+			continue
+		}
+		family, _, _ := strings.Cut(alias, "@")
+		if _, set := out["amiFamily"]; !set {
+			if f, ok := v1beta1AMIFamilies[strings.ToLower(family)]; ok {
+				out["amiFamily"] = f
+			}
+		}
+	}
+	if len(kept) > 0 {
+		out["amiSelectorTerms"] = kept
+	} else {
+		delete(out, "amiSelectorTerms")
+	}
+	return out
+}
+
+// template renders the NodePool template. For Auto Mode node classes,
+// requirement keys use the eks.amazonaws.com label domain.
+func template(ref schema.GroupVersionKind, p NodePoolParams, nodeClassRef map[string]any) map[string]any {
+	autoMode := ref.Group == AutoModeNodeClass.Group
+	return map[string]any{
+		"spec": map[string]any{
+			"nodeClassRef": nodeClassRef,
+			"requirements": requirements(p, autoMode),
+		},
+	}
+}
+
+// requirements renders the selection requirements; keys without values are
+// left out. With attributes, they replace the instance type names.
+func requirements(p NodePoolParams, autoMode bool) []any {
+	types := p.InstanceTypes
+	if len(p.Attributes) > 0 {
+		types = nil
+	}
+	reqs := []corev1.NodeSelectorRequirement{
+		{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: p.Architectures},
+		{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{p.CapacityType}},
+		{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: types},
+		{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: p.Zones},
+	}
+	reqs = append(reqs, attributeRequirements(p)...)
+	out := make([]any, 0, len(reqs))
+	for _, r := range reqs {
+		if len(r.Values) == 0 {
+			// Unconstrained, e.g. zones with NodeOverlay price hints.
+			continue
+		}
+		key := r.Key
+		if autoMode {
+			var ok bool
+			if key, ok = autoModeKey(key); !ok {
+				continue
+			}
+		}
+		values := make([]any, 0, len(r.Values))
+		for _, v := range r.Values {
+			values = append(values, v)
+		}
+		out = append(out, map[string]any{"key": key, "operator": string(r.Operator), "values": values})
+	}
+	return out
+}
+
+// autoModeInstanceLabels are the instance labels EKS Auto Mode NodePools
+// accept, in the eks.amazonaws.com domain.
+var autoModeInstanceLabels = map[string]bool{
+	"instance-hypervisor": true, "instance-encryption-in-transit-supported": true,
+	"instance-category": true, "instance-generation": true, "instance-family": true, "instance-size": true,
+	"instance-cpu": true, "instance-cpu-manufacturer": true, "instance-memory": true,
+	"instance-ebs-bandwidth": true, "instance-network-bandwidth": true, "instance-local-nvme": true,
+	"instance-gpu-name": true, "instance-gpu-manufacturer": true, "instance-gpu-count": true, "instance-gpu-memory": true,
+}
+
+// autoModeKey translates a requirement key for an Auto Mode NodePool:
+// karpenter.k8s.aws instance labels map to eks.amazonaws.com, and it reports
+// false for keys Auto Mode does not support.
+func autoModeKey(key string) (string, bool) {
+	name, ok := strings.CutPrefix(key, EC2NodeClass.Group+"/")
+	if !ok {
+		return key, true
+	}
+	if !autoModeInstanceLabels[name] {
+		return "", false
+	}
+	return AutoModeNodeClass.Group + "/" + name, true
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderNodePool(t *testing.T) {
+	p := NodePoolParams{
+		Name: "leftover-a", NodeClassName: "gpu", InstanceTypes: []string{"g5g.xlarge"}, Zones: []string{"us-east-1a"},
+		CapacityType: "spot", Architectures: []string{"arm64"},
+	}
+	cases := []struct {
+		name    string
+		version string
+		kind    NodeClassKind
+		wantRef map[string]any
+	}{
+		{"v1", APIVersionV1, EC2NodeClass,
+			map[string]any{"name": "gpu", "group": "karpenter.k8s.aws", "kind": "EC2NodeClass"}},
+		{"v1beta1", APIVersionV1beta1, EC2NodeClass,
+			map[string]any{"name": "gpu", "apiVersion": "karpenter.k8s.aws/v1beta1", "kind": "EC2NodeClass"}},
+		{"auto mode", APIVersionV1, AutoModeNodeClass,
+			map[string]any{"name": "gpu", "group": "eks.amazonaws.com", "kind": "NodeClass"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			api, err := NewAPI(tc.version, tc.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec := api.render.nodePoolSpec(api.nodeClassGVK, p)
+			tmpl := spec["template"].(map[string]any)["spec"].(map[string]any)
+			if got, has := spec["template"].(map[string]any)["metadata"]; has {
+				t.Errorf("template metadata = %v, want none", got)
+			}
+			if got := tmpl["nodeClassRef"]; !reflect.DeepEqual(got, tc.wantRef) {
+				t.Errorf("nodeClassRef = %v, want %v", got, tc.wantRef)
+			}
+			if got, has := spec["disruption"]; has {
+				t.Errorf("disruption = %v, want none", got)
+			}
+			arch := tmpl["requirements"].([]any)[0].(map[string]any)
+			if !reflect.DeepEqual(arch["values"], []any{"arm64"}) {
+				t.Errorf("arch requirement = %v, want arm64", arch)
+			}
+		})
+	}
+}
+
+func TestRenderNodeClassV1beta1(t *testing.T) {
+	spec := map[string]any{
+		"role": "KarpenterNodeRole",
+		"amiSelectorTerms": []any{
+			map[string]any{"alias": "bottlerocket@latest"},
+			map[string]any{"tags": map[string]any{"team": "ml"}},
+		},
+	}
+	got := v1beta1Renderer{}.nodeClassSpec(spec)
+	want := map[string]any{
+		"role":             "KarpenterNodeRole",
+		"amiFamily":        "Bottlerocket",
+		"amiSelectorTerms": []any{map[string]any{"tags": map[string]any{"team": "ml"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeClassSpec = %v, want %v", got, want)
+	}
+
+	aliasOnly := v1beta1Renderer{}.nodeClassSpec(map[string]any{"amiSelectorTerms": []any{map[string]any{"alias": "al2023@v20240807"}}})
+	if _, has := aliasOnly["amiSelectorTerms"]; has || aliasOnly["amiFamily"] != "AL2023" {
+		t.Errorf("nodeClassSpec(alias only) = %v, want amiFamily AL2023 without amiSelectorTerms", aliasOnly)
+	}
+}
+
+func TestAutoModeKey(t *testing.T) {
+	cases := []struct {
+		key, want string
+		ok        bool
+	}{
+		{"karpenter.k8s.aws/instance-gpu-count", "eks.amazonaws.com/instance-gpu-count", true},
+		{"karpenter.k8s.aws/instance-accelerator-name", "", false},
+		{"topology.kubernetes.io/zone", "topology.kubernetes.io/zone", true},
+	}
+	for _, tc := range cases {
+		if got, ok := autoModeKey(tc.key); got != tc.want || ok != tc.ok {
+			t.Errorf("autoModeKey(%s) = %s, %v; want %s, %v", tc.key, got, ok, tc.want, tc.ok)
+		}
+	}
+}
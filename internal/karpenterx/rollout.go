@@ -0,0 +1,160 @@
+package karpenterx
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LabelNodePool is the label Karpenter sets on nodes to their NodePool.
+	LabelNodePool = "karpenter.sh/nodepool"
+	// AnnotationDrainingSince marks a NodePool drained by DrainNodePool.
+	AnnotationDrainingSince = "gpu.devplatforms.io/draining-since"
+	// drainFieldOwner owns the drain limits, separately from the NodePool spec.
+	drainFieldOwner = "leftover-rollout"
+)
+
+// Offering is an instance type in a zone.
+type Offering struct {
+	InstanceType string
+	Zone         string
+}
+
+// AddOffering extends the requirements so o, launched as arch, stays allowed.
+func (p *NodePoolParams) AddOffering(o Offering, arch string) {
+	p.InstanceTypes = appendMissing(p.InstanceTypes, o.InstanceType)
+	p.Zones = appendMissing(p.Zones, o.Zone)
+	p.Architectures = appendMissing(p.Architectures, arch)
+}
+
+func appendMissing(s []string, v string) []string {
+	if v == "" || slices.Contains(s, v) {
+		return s
+	}
+	return append(s, v)
+}
+
+// NodeInfo is the offering, architecture and instance attributes of a node.
+type NodeInfo struct {
+	Offering
+	Architecture string
+	// Attributes are the node's attribute labels (see AddAttributes).
+	Attributes map[string]string
+}
+
+// PoolNodes lists the nodes Karpenter launched for NodePool name.
+func PoolNodes(ctx context.Context, c client.Client, name string) ([]NodeInfo, error) {
+	var nodes corev1.NodeList
+	if err := c.List(ctx, &nodes, client.MatchingLabels{LabelNodePool: name}); err != nil {
+		return nil, err
+	}
+	out := make([]NodeInfo, 0, len(nodes.Items))
+	for _, n := range nodes.Items {
+		out = append(out, NodeInfo{
+			Offering: Offering{
+				InstanceType: n.Labels[corev1.LabelInstanceTypeStable],
+				Zone:         n.Labels[corev1.LabelTopologyZone],
+			},
+			Architecture: n.Labels[corev1.LabelArchStable],
+			Attributes:   nodeAttributes(n.Labels),
+		})
+	}
+	return out, nil
+}
+
+// Retained is a previously selected offering kept in the requirements.
+type Retained struct {
+	Offering
+	Architecture string
+	Attributes   map[string]string
+	Since        time.Time
+	Nodes        int32
+}
+
+// Retain returns the offerings to keep next to selected: the earlier retained
+// ones and the previous selection, as long as they still have nodes and were
+// left less than maxAge ago.
+func Retain(retained []Retained, previous []Offering, selected Offering, nodes []NodeInfo, now time.Time, maxAge time.Duration) []Retained {
+	count := make(map[Offering]int32, len(nodes))
+	last := make(map[Offering]NodeInfo, len(nodes))
+	for _, n := range nodes {
+		count[n.Offering]++
+		last[n.Offering] = n
+	}
+	seen := map[Offering]bool{selected: true}
+	var out []Retained
+	keep := func(r Retained) {
+		if seen[r.Offering] || count[r.Offering] == 0 || now.Sub(r.Since) > maxAge {
+			return
+		}
+		seen[r.Offering] = true
+		r.Nodes = count[r.Offering]
+		r.Architecture = last[r.Offering].Architecture
+		r.Attributes = last[r.Offering].Attributes
+		out = append(out, r)
+	}
+	for _, r := range retained {
+		keep(r)
+	}
+	for _, o := range previous {
+		keep(Retained{Offering: o, Since: now})
+	}
+	return out
+}
+
+// OwnedNodePool is a NodePool controlled by a LeftoverNodePool.
+type OwnedNodePool struct {
+	Name string
+	// DrainingSince is set while the NodePool is drained.
+	DrainingSince *time.Time
+}
+
+// OwnedNodePools lists the NodePools labeled managed-by: leftover that are
+// controlled by owner.
+func (a *API) OwnedNodePools(ctx context.Context, c client.Client, owner client.Object) ([]OwnedNodePool, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(a.nodePoolGVK.GroupVersion().WithKind(a.nodePoolGVK.Kind + "List"))
+	if err := c.List(ctx, list, client.MatchingLabels{"managed-by": "leftover"}); err != nil {
+		return nil, err
+	}
+	var out []OwnedNodePool
+	for i := range list.Items {
+		u := &list.Items[i]
+		if ref := metav1.GetControllerOf(u); ref == nil || ref.UID != owner.GetUID() {
+			continue
+		}
+		np := OwnedNodePool{Name: u.GetName()}
+		if since, err := time.Parse(time.RFC3339, u.GetAnnotations()[AnnotationDrainingSince]); err == nil {
+			np.DrainingSince = &since
+		}
+		out = append(out, np)
+	}
+	return out, nil
+}
+
+// DrainNodePool stops NodePool name from launching nodes by setting its CPU
+// limit to zero; existing nodes are left to terminate on their own.
+func (a *API) DrainNodePool(ctx context.Context, c client.Client, name string, since time.Time) error {
+	u := a.drainPatch(name)
+	u.SetAnnotations(map[string]string{AnnotationDrainingSince: since.UTC().Format(time.RFC3339)})
+	u.Object["spec"] = map[string]any{"limits": map[string]any{"cpu": "0"}}
+	return c.Patch(ctx, u, client.Apply, client.FieldOwner(drainFieldOwner), client.ForceOwnership)
+}
+
+// UndrainNodePool removes the limit and annotation set by DrainNodePool.
+func (a *API) UndrainNodePool(ctx context.Context, c client.Client, name string) error {
+	return c.Patch(ctx, a.drainPatch(name), client.Apply, client.FieldOwner(drainFieldOwner), client.ForceOwnership)
+}
+
+func (a *API) drainPatch(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(a.nodePoolGVK)
+	u.SetName(name)
+	return u
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetain(t *testing.T) {
+	now := time.Date(2025, 9, 16, 12, 0, 0, 0, time.UTC)
+	g5a := Offering{InstanceType: "g5.xlarge", Zone: "us-east-1a"}
+	g5b := Offering{InstanceType: "g5.xlarge", Zone: "us-east-1b"}
+	g6a := Offering{InstanceType: "g6.xlarge", Zone: "us-east-1a"}
+	g5g := Offering{InstanceType: "g5g.xlarge", Zone: "us-east-1a"}
+	nodes := []NodeInfo{
+		{Offering: g5a, Architecture: "amd64"},
+		{Offering: g5a, Architecture: "amd64"},
+		{Offering: g5g, Architecture: "arm64"},
+		{Offering: g6a, Architecture: "amd64"},
+	}
+	cases := []struct {
+		name     string
+		retained []Retained
+		previous []Offering
+		selected Offering
+		want     []Retained
+	}{
+		{"previous selection with nodes", nil, []Offering{g5a}, g6a,
+			[]Retained{{Offering: g5a, Architecture: "amd64", Since: now, Nodes: 2}}},
+		{"previous selection without nodes", nil, []Offering{g5b}, g6a, nil},
+		{"earlier offering keeps its age", []Retained{{Offering: g5g, Since: now.Add(-time.Hour)}}, []Offering{g5a}, g6a,
+			[]Retained{{Offering: g5g, Architecture: "arm64", Since: now.Add(-time.Hour), Nodes: 1}, {Offering: g5a, Architecture: "amd64", Since: now, Nodes: 2}}},
+		{"older than max age", []Retained{{Offering: g5g, Since: now.Add(-25 * time.Hour)}}, nil, g6a, nil},
+		{"selected again", []Retained{{Offering: g5a, Since: now.Add(-time.Hour)}}, []Offering{g6a}, g5a,
+			[]Retained{{Offering: g6a, Architecture: "amd64", Since: now, Nodes: 1}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Retain(tc.retained, tc.previous, tc.selected, nodes, now, 24*time.Hour)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Retain = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddOffering(t *testing.T) {
+	p := NodePoolParams{InstanceTypes: []string{"g6.xlarge"}, Zones: []string{"us-east-1a"}, Architectures: []string{"amd64"}}
+	p.AddOffering(Offering{InstanceType: "g5g.xlarge", Zone: "us-east-1a"}, "arm64")
+	p.AddOffering(Offering{InstanceType: "g6.xlarge", Zone: "us-east-1b"}, "amd64")
+	want := NodePoolParams{
+		InstanceTypes: []string{"g6.xlarge", "g5g.xlarge"},
+		Zones:         []string{"us-east-1a", "us-east-1b"},
+		Architectures: []string{"amd64", "arm64"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("AddOffering = %+v, want %+v", p, want)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// Karpenter API versions Leftover can render, most preferred first.
+const (
+	APIVersionV1      = "v1"
+	APIVersionV1beta1 = "v1beta1"
+)
+
+// ReasonAPIUnsupported is the Ready condition reason when the cluster serves
+// no supported Karpenter API.
+const ReasonAPIUnsupported = "KarpenterAPIUnsupported"
+
+// ErrAPIUnsupported means the cluster serves no supported Karpenter API.
+var ErrAPIUnsupported = errors.New("no supported Karpenter API is served (need karpenter.sh NodePools v1 or v1beta1 " +
+	"with karpenter.k8s.aws EC2NodeClasses of the same version or EKS Auto Mode NodeClasses)")
+
+// ReasonNodeOverlayUnsupported is the Ready condition reason when NodeOverlay
+// output is requested but the cluster does not serve NodeOverlays.
+const ReasonNodeOverlayUnsupported = "NodeOverlayUnsupported"
+
+// ErrNodeOverlayUnsupported means the cluster serves no NodeOverlays.
+var ErrNodeOverlayUnsupported = errors.New("karpenter.sh/v1alpha1 NodeOverlays are not served " +
+	"(needs Karpenter with the NodeOverlay feature gate enabled)")
+
+// NodeClassKind identifies a node class kind NodePools can reference.
+type NodeClassKind struct {
+	Group string
+	Kind  string
+}
+
+// Node class kinds: Karpenter's AWS provider EC2NodeClass and the EKS Auto
+// Mode NodeClass (served as v1 only).
+var (
+	EC2NodeClass      = NodeClassKind{Group: "karpenter.k8s.aws", Kind: "EC2NodeClass"}
+	AutoModeNodeClass = NodeClassKind{Group: "eks.amazonaws.com", Kind: "NodeClass"}
+)
+
+func (k NodeClassKind) String() string { return k.Kind + "." + k.Group }
+
+var renderers = map[string]renderer{
+	APIVersionV1:      v1Renderer{},
+	APIVersionV1beta1: v1beta1Renderer{},
+}
+
+// NewAPI returns the API for version (APIVersionV1 or APIVersionV1beta1)
+// serving the given node class kinds; the first kind is used unless
+// ForNodeClass selects another. EC2NodeClasses share the NodePool version,
+// Auto Mode NodeClasses are v1.
+func NewAPI(version string, kinds ...NodeClassKind) (*API, error) {
+	r, ok := renderers[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Karpenter API version %q", version)
+	}
+	if len(kinds) == 0 {
+		kinds = []NodeClassKind{EC2NodeClass}
+	}
+	a := &API{
+		Version:          version,
+		nodePoolGVK:      schema.GroupVersionKind{Group: "karpenter.sh", Version: version, Kind: "NodePool"},
+		nodeClassVersion: map[NodeClassKind]string{},
+		render:           r,
+	}
+	for _, k := range kinds {
+		v := version
+		if k == AutoModeNodeClass {
+			v = APIVersionV1
+		}
+		a.nodeClassVersion[k] = v
+	}
+	a.nodeClassGVK = schema.GroupVersionKind{Group: kinds[0].Group, Version: a.nodeClassVersion[kinds[0]], Kind: kinds[0].Kind}
+	return a, nil
+}
+
+// ForNodeClass returns a copy of a reading, writing and referencing node
+// classes of kind. It wraps ErrAPIUnsupported when kind is not served.
+func (a *API) ForNodeClass(kind NodeClassKind) (*API, error) {
+	v, ok := a.nodeClassVersion[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not served", ErrAPIUnsupported, kind)
+	}
+	out := *a
+	out.nodeClassGVK = schema.GroupVersionKind{Group: kind.Group, Version: v, Kind: kind.Kind}
+	return &out, nil
+}
+
+// Detector finds the Karpenter API version served by the cluster. A detected
+// version is kept for the life of the process; a failed detection is retried
+// on the next call, so Karpenter may be installed after Leftover.
+type Detector struct {
+	disc discovery.DiscoveryInterface
+
+	mu  sync.Mutex
+	api *API
+}
+
+// NewDetector returns a Detector using the discovery client disc.
+func NewDetector(disc discovery.DiscoveryInterface) *Detector {
+	return &Detector{disc: disc}
+}
+
+// API returns the preferred Karpenter API the cluster serves NodePools and a
+// node class kind in. It returns ErrAPIUnsupported when there is none or d is
+// nil.
+func (d *Detector) API() (*API, error) {
+	if d == nil {
+		return nil, ErrAPIUnsupported
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.api != nil {
+		return d.api, nil
+	}
+	for _, version := range []string{APIVersionV1, APIVersionV1beta1} {
+		pools, err := d.serves("karpenter.sh/"+version, "nodepools")
+		if err != nil {
+			return nil, err
+		}
+		if !pools {
+			continue
+		}
+		var kinds []NodeClassKind
+		ec2, err := d.serves("karpenter.k8s.aws/"+version, "ec2nodeclasses")
+		if err != nil {
+			return nil, err
+		}
+		if ec2 {
+			kinds = append(kinds, EC2NodeClass)
+		}
+		if version == APIVersionV1 {
+			auto, err := d.serves("eks.amazonaws.com/v1", "nodeclasses")
+			if err != nil {
+				return nil, err
+			}
+			if auto {
+				kinds = append(kinds, AutoModeNodeClass)
+			}
+		}
+		if len(kinds) > 0 {
+			api, err := NewAPI(version, kinds...)
+			if err != nil {
+				return nil, err
+			}
+			if version == APIVersionV1 {
+				if api.NodeOverlays, err = d.serves(nodeOverlayGVK.GroupVersion().String(), "nodeoverlays"); err != nil {
+					return nil, err
+				}
+			}
+			d.api = api
+			return api, nil
+		}
+	}
+	return nil, ErrAPIUnsupported
+}
+
+func (d *Detector) serves(groupVersion, resource string) (bool, error) {
+	list, err := d.disc.ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("discovering %s: %w", groupVersion, err)
+	}
+	for _, r := range list.APIResources {
+		if r.Name == resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package karpenterx
+
+import (
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func served(groupVersion, resource string) *metav1.APIResourceList {
+	return &metav1.APIResourceList{GroupVersion: groupVersion, APIResources: []metav1.APIResource{{Name: resource}}}
+}
+
+func TestDetectorAPI(t *testing.T) {
+	cases := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		want      string
+	}{
+		{"v1 preferred", []*metav1.APIResourceList{
+			served("karpenter.sh/v1", "nodepools"), served("karpenter.k8s.aws/v1", "ec2nodeclasses"),
+			served("karpenter.sh/v1beta1", "nodepools"), served("karpenter.k8s.aws/v1beta1", "ec2nodeclasses"),
+		}, APIVersionV1},
+		{"v1beta1", []*metav1.APIResourceList{
+			served("karpenter.sh/v1beta1", "nodepools"), served("karpenter.k8s.aws/v1beta1", "ec2nodeclasses"),
+		}, APIVersionV1beta1},
+		{"auto mode", []*metav1.APIResourceList{
+			served("karpenter.sh/v1", "nodepools"), served("eks.amazonaws.com/v1", "nodeclasses"),
+		}, APIVersionV1},
+		{"mixed versions", []*metav1.APIResourceList{
+			served("karpenter.sh/v1", "nodepools"), served("karpenter.k8s.aws/v1beta1", "ec2nodeclasses"),
+		}, ""},
+		{"not installed", nil, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			disc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tc.resources}}
+			api, err := NewDetector(disc).API()
+			if tc.want == "" {
+				if !errors.Is(err, ErrAPIUnsupported) {
+					t.Fatalf("API() = %v, %v; want ErrAPIUnsupported", api, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("API() failed: %v", err)
+			}
+			if api.Version != tc.want || api.nodePoolGVK.Version != tc.want || api.nodeClassGVK.Version != tc.want {
+				t.Errorf("API() = %+v, want version %s", api, tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectorRetriesUntilInstalled(t *testing.T) {
+	fake := &clienttesting.Fake{}
+	d := NewDetector(&fakediscovery.FakeDiscovery{Fake: fake})
+	if _, err := d.API(); !errors.Is(err, ErrAPIUnsupported) {
+		t.Fatalf("API() before install = %v, want ErrAPIUnsupported", err)
+	}
+	fake.Resources = []*metav1.APIResourceList{served("karpenter.sh/v1", "nodepools"), served("karpenter.k8s.aws/v1", "ec2nodeclasses")}
+	if api, err := d.API(); err != nil || api.Version != APIVersionV1 {
+		t.Fatalf("API() after install = %v, %v; want v1", api, err)
+	}
+}
+
+func TestAPIForNodeClass(t *testing.T) {
+	api, err := NewAPI(APIVersionV1beta1, EC2NodeClass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.ForNodeClass(AutoModeNodeClass); !errors.Is(err, ErrAPIUnsupported) {
+		t.Errorf("ForNodeClass(Auto Mode) on v1beta1 = %v, want ErrAPIUnsupported", err)
+	}
+
+	api, err = NewAPI(APIVersionV1, EC2NodeClass, AutoModeNodeClass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auto, err := api.ForNodeClass(AutoModeNodeClass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !auto.AutoMode() || api.AutoMode() {
+		t.Errorf("AutoMode() = %v for Auto Mode and %v for EC2NodeClass", auto.AutoMode(), api.AutoMode())
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics holds the Prometheus collectors exported by the operator.
+// They are registered with the controller-runtime registry and served on the
+// manager's metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// AWSCacheRequests counts market data lookups by data kind and result
+	// (hit, miss or coalesced onto an in-flight call).
+	AWSCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "leftover_aws_cache_requests_total",
+		Help: "AWS market data cache lookups by data kind and result (hit, miss, coalesced).",
+	}, []string{"kind", "result"})
+
+	// PlacementScoreRequests counts GetSpotPlacementScores lookups by outcome:
+	// fresh (served from cache), sent (call made), stale (budget exhausted,
+	// last known scores used) or missing (budget exhausted, nothing known).
+	PlacementScoreRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "leftover_placement_score_requests_total",
+		Help: "Spot placement score lookups by region and outcome (fresh, sent, stale, missing).",
+	}, []string{"region", "outcome"})
+
+	// SpotPriceVolatility is the coefficient of variation of an offering's
+	// hourly-sampled price over the retained history.
+	SpotPriceVolatility = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leftover_spot_price_volatility_ratio",
+		Help: "Coefficient of variation of the Spot price over the retained history.",
+	}, []string{"region", "product", "instance_type", "zone"})
+
+	// SpotPriceTrend is the relative price change over the last day.
+	SpotPriceTrend = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leftover_spot_price_trend_ratio_per_day",
+		Help: "Relative Spot price change over the last 24h.",
+	}, []string{"region", "product", "instance_type", "zone"})
+
+	// SpotPriceForecast is the EWMA forecast of an offering's price.
+	SpotPriceForecast = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leftover_spot_price_forecast_usd",
+		Help: "EWMA forecast of the Spot price over the default horizon, in USD per hour.",
+	}, []string{"region", "product", "instance_type", "zone"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		AWSCacheRequests,
+		PlacementScoreRequests,
+		SpotPriceVolatility,
+		SpotPriceTrend,
+		SpotPriceForecast,
+	)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	base := time.Date(2025, time.September, 16, 19, 4, 7, 0, time.UTC) // Tuesday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 9, 16, 19, 5, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 9, 16, 19, 15, 0, 0, time.UTC)},
+		{"0 22 * * MON-FRI", time.Date(2025, 9, 16, 22, 0, 0, 0, time.UTC)},
+		{"0 2 * * sat,sun", time.Date(2025, 9, 20, 2, 0, 0, 0, time.UTC)},
+		{"30 1 1 jan *", time.Date(2026, 1, 1, 1, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 9, 21, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 9, 17, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches (the 1st, or a Monday).
+		{"0 0 1 * 1", time.Date(2025, 9, 22, 0, 0, 0, 0, time.UTC)},
+		{"10-20/5 19 * * *", time.Date(2025, 9, 16, 19, 10, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Errorf("%s: got %s, want %s", tc.spec, got, tc.want)
+		}
+	}
+
+	s, _ := Parse("0 0 30 2 *")
+	if got := s.Next(base); !got.IsZero() {
+		t.Errorf("February 30th: got %s, want zero", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	w, err := NewWindow("0 22 * * MON-FRI", "4h", "Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin := w.Location
+	cases := []struct {
+		now  time.Time
+		open bool
+	}{
+		{time.Date(2025, 9, 16, 21, 59, 0, 0, berlin), false},
+		{time.Date(2025, 9, 16, 22, 0, 0, 0, berlin), true},
+		// Tuesday's window runs into Wednesday morning.
+		{time.Date(2025, 9, 17, 1, 59, 59, 0, berlin), true},
+		{time.Date(2025, 9, 17, 2, 0, 0, 0, berlin), false},
+		// Saturday night has no window, but Friday's is still open.
+		{time.Date(2025, 9, 20, 1, 0, 0, 0, berlin), true},
+		{time.Date(2025, 9, 20, 22, 30, 0, 0, berlin), false},
+	}
+	for _, tc := range cases {
+		if got := w.Open(tc.now.UTC()); got != tc.open {
+			t.Errorf("%s: open = %v, want %v", tc.now, got, tc.open)
+		}
+	}
+
+	next := NextOpen([]*Window{w}, time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 9, 22, 22, 0, 0, 0, berlin); !next.Equal(want) {
+		t.Errorf("next open: got %s, want %s", next, want)
+	}
+	if got, want := w.NextChange(time.Date(2025, 9, 17, 1, 0, 0, 0, berlin)), time.Date(2025, 9, 17, 2, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("next change while open: got %s, want %s", got, want)
+	}
+	if got, want := w.NextChange(time.Date(2025, 9, 17, 12, 0, 0, 0, berlin)), time.Date(2025, 9, 17, 22, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("next change while closed: got %s, want %s", got, want)
+	}
+}
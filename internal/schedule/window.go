@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window is a recurring time window: it opens on every activation of a
+// schedule, evaluated in a location, and stays open for a duration.
+type Window struct {
+	Schedule *Schedule
+	Duration time.Duration
+	Location *time.Location
+}
+
+// NewWindow parses a window from its cron schedule, duration (e.g. "4h") and
+// IANA time zone; an empty time zone means UTC.
+func NewWindow(cron, duration, timeZone string) (*Window, error) {
+	s, err := Parse(cron)
+	if err != nil {
+		return nil, err
+	}
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return nil, fmt.Errorf("duration %q: %w", duration, err)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("duration %q must be positive", duration)
+	}
+	loc := time.UTC
+	if timeZone != "" {
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return nil, fmt.Errorf("time zone %q: %w", timeZone, err)
+		}
+	}
+	return &Window{Schedule: s, Duration: d, Location: loc}, nil
+}
+
+// Open reports whether now is within an occurrence of the window.
+func (w *Window) Open(now time.Time) bool {
+	start := w.Schedule.Next(now.In(w.Location).Add(-w.Duration))
+	return !start.IsZero() && !start.After(now)
+}
+
+// NextOpen returns the next time after now the window opens, or the zero
+// time if the schedule never fires.
+func (w *Window) NextOpen(now time.Time) time.Time {
+	return w.Schedule.Next(now.In(w.Location))
+}
+
+// NextChange returns the next time after now the window may open or close:
+// the next start or the end of the occurrence open at now, whichever is
+// first. It is the zero time if the window never changes.
+func (w *Window) NextChange(now time.Time) time.Time {
+	next := w.NextOpen(now)
+	if start := w.Schedule.Next(now.In(w.Location).Add(-w.Duration)); !start.IsZero() && !start.After(now) {
+		if end := start.Add(w.Duration); next.IsZero() || end.Before(next) {
+			next = end
+		}
+	}
+	return next
+}
+
+// AnyOpen reports whether now is within any of the windows.
+func AnyOpen(windows []*Window, now time.Time) bool {
+	for _, w := range windows {
+		if w.Open(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the earliest time after now any of the windows opens, or
+// the zero time if none ever does.
+func NextOpen(windows []*Window, now time.Time) time.Time {
+	var next time.Time
+	for _, w := range windows {
+		if t := w.NextOpen(now); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"path"
 	"regexp"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	gpuv1alpha1 "github.com/devplatformsolutions/leftover/api/v1alpha1"
+	"github.com/devplatformsolutions/leftover/internal/schedule"
 )
 
 // log is for logging in this package.
@@ -170,9 +172,247 @@ func validateSpec(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
 	if s.RequeueMinutes < 1 {
 		return fmt.Errorf("spec.requeueMinutes must be >= 1")
 	}
+	for _, validate := range []func(*gpuv1alpha1.LeftoverNodePoolSpec) error{
+		validateNodeClass,
+		validateRollout,
+		validateDiversification,
+		validateRequirementMode,
+		validateDemand,
+		validateChangeWindows,
+		validateSchedules,
+		validatePin,
+		validateMode,
+		validateApproval,
+	} {
+		if err := validate(s); err != nil {
+			return err
+		}
+	}
 	// Cross-field: if on-demand, fallback makes no sense
 	if s.CapacityType == "on-demand" && s.OnDemandFallback {
 		return fmt.Errorf("spec.onDemandFallback cannot be true when spec.capacityType is \"on-demand\"")
 	}
 	return nil
 }
+
+// validateRollout checks spec.rolloutStrategy.
+func validateRollout(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	rs := s.RolloutStrategy
+	if rs == nil {
+		return nil
+	}
+	if rs.MaxAge != "" {
+		if d, err := time.ParseDuration(rs.MaxAge); err != nil || d <= 0 {
+			return fmt.Errorf("spec.rolloutStrategy.maxAge must be a positive duration (e.g., \"24h\")")
+		}
+	}
+	if rs.Type == gpuv1alpha1.RolloutBlueGreen && s.NodePoolRef != nil {
+		return fmt.Errorf("spec.rolloutStrategy BlueGreen creates NodePools and cannot be used with spec.nodePoolRef")
+	}
+	if rs.Type == gpuv1alpha1.RolloutAdditive && s.OutputMode == gpuv1alpha1.OutputNodeOverlay {
+		return fmt.Errorf("spec.rolloutStrategy Additive does not apply to spec.outputMode NodeOverlay")
+	}
+	return nil
+}
+
+// validateRequirementMode checks spec.requirementMode. Attributes are
+// rendered into NodePools Leftover owns with pinned output only.
+func validateRequirementMode(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	if s.RequirementMode != gpuv1alpha1.RequirementsAttributes {
+		return nil
+	}
+	if s.NodePoolRef != nil || s.OutputMode == gpuv1alpha1.OutputNodeOverlay {
+		return fmt.Errorf("spec.requirementMode Attributes cannot be used with spec.nodePoolRef or spec.outputMode NodeOverlay")
+	}
+	return nil
+}
+
+// validateDemand checks spec.demand.
+func validateDemand(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	d := s.Demand
+	if d == nil {
+		return nil
+	}
+	if (d.Type == gpuv1alpha1.DemandPrometheus) != (d.Query != "") {
+		return fmt.Errorf("spec.demand.query is required with type Prometheus and only allowed there")
+	}
+	if d.GPUsPerNode != 0 && d.Type != gpuv1alpha1.DemandPendingPods {
+		return fmt.Errorf("spec.demand.gpusPerNode only applies to type PendingPods")
+	}
+	if d.MaxTargetCount != 0 && d.MaxTargetCount < s.TargetCount {
+		return fmt.Errorf("spec.demand.maxTargetCount must be >= spec.targetCount (%d)", s.TargetCount)
+	}
+	return nil
+}
+
+// validateChangeWindows checks spec.changeWindows and spec.emergencyScoreFloor.
+func validateChangeWindows(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	if len(s.ChangeWindows) == 0 {
+		if s.EmergencyScoreFloor != 0 {
+			return fmt.Errorf("spec.emergencyScoreFloor requires spec.changeWindows")
+		}
+		return nil
+	}
+	if s.OutputMode == gpuv1alpha1.OutputNodeOverlay {
+		return fmt.Errorf("spec.changeWindows do not apply to spec.outputMode NodeOverlay")
+	}
+	for i, cw := range s.ChangeWindows {
+		if _, err := schedule.NewWindow(cw.Schedule, cw.Duration, cw.TimeZone); err != nil {
+			return fmt.Errorf("spec.changeWindows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateSchedules checks spec.schedules.
+func validateSchedules(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	seen := make(map[string]bool, len(s.Schedules))
+	for i, cs := range s.Schedules {
+		if cs.Name == "" || seen[cs.Name] {
+			return fmt.Errorf("spec.schedules[%d].name must be set and unique", i)
+		}
+		seen[cs.Name] = true
+		if _, err := schedule.NewWindow(cs.Schedule, cs.Duration, cs.TimeZone); err != nil {
+			return fmt.Errorf("spec.schedules[%d]: %w", i, err)
+		}
+		if cs.CapacityType == "" && cs.MinSpotScore == nil && cs.Strategy == nil && cs.Limits == nil {
+			return fmt.Errorf("spec.schedules[%d] needs at least one override", i)
+		}
+		if cs.CapacityType == "on-demand" && s.OnDemandFallback {
+			return fmt.Errorf("spec.schedules[%d].capacityType \"on-demand\" cannot be combined with spec.onDemandFallback", i)
+		}
+		for name, q := range cs.Limits {
+			if q.Sign() < 0 {
+				return fmt.Errorf("spec.schedules[%d].limits[%s] must not be negative", i, name)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePin checks spec.pin.
+func validatePin(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	p := s.Pin
+	if p == nil {
+		return nil
+	}
+	if p.InstanceType == "" || p.Zone == "" {
+		return fmt.Errorf("spec.pin needs instanceType and zone")
+	}
+	if p.CapacityType == "on-demand" && s.OnDemandFallback {
+		return fmt.Errorf("spec.pin.capacityType \"on-demand\" cannot be combined with spec.onDemandFallback")
+	}
+	return nil
+}
+
+// validateMode checks spec.mode.
+func validateMode(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	if s.Mode == gpuv1alpha1.ModeShadow && s.NodePoolRef == nil {
+		return fmt.Errorf("spec.mode Shadow compares against spec.nodePoolRef, which must be set")
+	}
+	return nil
+}
+
+// validateApproval checks spec.approvalPolicy and spec.approvalTimeout.
+func validateApproval(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	if s.ApprovalTimeout != "" {
+		if d, err := time.ParseDuration(s.ApprovalTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("spec.approvalTimeout must be a positive duration (e.g., \"24h\")")
+		}
+	}
+	if s.ApprovalPolicy == gpuv1alpha1.ApprovalManual && s.OutputMode == gpuv1alpha1.OutputNodeOverlay {
+		return fmt.Errorf("spec.approvalPolicy Manual does not apply to spec.outputMode NodeOverlay")
+	}
+	return nil
+}
+
+// validateDiversification checks spec.diversification.
+func validateDiversification(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	d := s.Diversification
+	if d == nil {
+		return nil
+	}
+	if d.Pools < 2 {
+		return fmt.Errorf("spec.diversification.pools must be >= 2")
+	}
+	if s.NodePoolRef != nil {
+		return fmt.Errorf("spec.diversification creates NodePools and cannot be used with spec.nodePoolRef")
+	}
+	if s.OutputMode == gpuv1alpha1.OutputNodeOverlay {
+		return fmt.Errorf("spec.diversification cannot be used with spec.outputMode NodeOverlay")
+	}
+	if rs := s.RolloutStrategy; rs != nil && rs.Type == gpuv1alpha1.RolloutAdditive {
+		return fmt.Errorf("spec.rolloutStrategy Additive does not apply to diversified NodePools")
+	}
+	if len(d.Allocation) > 0 {
+		if len(d.Allocation) != d.Pools {
+			return fmt.Errorf("spec.diversification.allocation needs one entry per pool (%d)", d.Pools)
+		}
+		var sum int32
+		for _, a := range d.Allocation {
+			if a < 0 {
+				return fmt.Errorf("spec.diversification.allocation entries must be >= 0")
+			}
+			sum += a
+		}
+		if sum == 0 {
+			return fmt.Errorf("spec.diversification.allocation must not be all zero")
+		}
+	}
+	for name, q := range d.Limits {
+		if q.Sign() < 0 {
+			return fmt.Errorf("spec.diversification.limits[%s] must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// validateNodeClass checks the node class sources: none with nodePoolRef,
+// otherwise at most one of name, selector or template, and else mappings.
+func validateNodeClass(s *gpuv1alpha1.LeftoverNodePoolSpec) error {
+	sources := 0
+	for _, set := range []bool{s.NodeClassName != "", len(s.NodeClassSelector) > 0, s.NodeClassTemplate != nil} {
+		if set {
+			sources++
+		}
+	}
+	if s.NodePoolRef != nil {
+		if sources > 0 || len(s.NodeClassMappings) > 0 {
+			return fmt.Errorf("spec.nodePoolRef uses the node class of the adopted NodePool; " +
+				"spec.nodeClassName, spec.nodeClassSelector, spec.nodeClassTemplate and spec.nodeClassMappings must not be set")
+		}
+		return nil
+	}
+	if sources > 1 || (sources == 0 && len(s.NodeClassMappings) == 0) {
+		return fmt.Errorf("exactly one of spec.nodeClassName, spec.nodeClassSelector or spec.nodeClassTemplate must be set, unless spec.nodeClassMappings is")
+	}
+	for i, m := range s.NodeClassMappings {
+		if (m.NodeClassName == "") == (len(m.NodeClassSelector) == 0) {
+			return fmt.Errorf("spec.nodeClassMappings[%d] must set exactly one of nodeClassName or nodeClassSelector", i)
+		}
+		if len(m.Patterns) == 0 {
+			return fmt.Errorf("spec.nodeClassMappings[%d].patterns must not be empty", i)
+		}
+		for _, p := range m.Patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("spec.nodeClassMappings[%d].patterns contains invalid pattern %q: %w", i, p, err)
+			}
+		}
+	}
+	autoMode := s.NodeClassRef != nil && s.NodeClassRef.Kind == gpuv1alpha1.NodeClassKindAutoMode
+	if autoMode && s.NodeClassTemplate != nil {
+		return fmt.Errorf("spec.nodeClassTemplate renders an EC2NodeClass and cannot be used with spec.nodeClassRef kind NodeClass")
+	}
+	if t := s.NodeClassTemplate; t != nil {
+		if (t.Role == "") == (t.InstanceProfile == "") {
+			return fmt.Errorf("spec.nodeClassTemplate must set exactly one of role or instanceProfile")
+		}
+		if len(t.AMISelectorTerms) == 0 {
+			return fmt.Errorf("spec.nodeClassTemplate.amiSelectorTerms must not be empty")
+		}
+		if len(s.SubnetSelectorTags) == 0 || len(s.SecurityGroupSelectorTags) == 0 {
+			return fmt.Errorf("spec.subnetSelectorTags and spec.securityGroupSelectorTags are required with spec.nodeClassTemplate")
+		}
+	}
+	return nil
+}